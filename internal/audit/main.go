@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// Event is a security relevant decision made about withdrawal request
+type Event struct {
+	Type      string                 `json:"type"`
+	RequestID string                 `json:"request_id"`
+	Asset     string                 `json:"asset"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	Time      time.Time              `json:"time"`
+}
+
+// Recorder records audit events
+type Recorder interface {
+	Record(event Event)
+}
+
+type recorder struct {
+	log  *logan.Entry
+	path string
+	mu   sync.Mutex
+}
+
+// New creates recorder which logs events and appends them to the file by path, if one is set
+func New(log *logan.Entry, path string) Recorder {
+	return &recorder{
+		log:  log.WithField("service", "audit"),
+		path: path,
+	}
+}
+
+// Record logs event and appends it to audit trail. Failure to persist event is logged, but never blocks
+// the caller: the decision being audited was already made.
+func (r *recorder) Record(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	fields := logan.F{
+		"audit_event": event.Type,
+		"request_id":  event.RequestID,
+		"asset":       event.Asset,
+	}
+	r.log.WithFields(fields).WithFields(event.Details).Info("audit event")
+
+	if r.path == "" {
+		return
+	}
+
+	if err := r.append(event); err != nil {
+		r.log.WithFields(fields).WithError(err).Error("failed to persist audit event")
+	}
+}
+
+func (r *recorder) append(event Event) error {
+	raw, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal event")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to open audit file", logan.F{"path": r.path})
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(raw, '\n')); err != nil {
+		return errors.Wrap(err, "failed to write audit file", logan.F{"path": r.path})
+	}
+
+	return file.Sync()
+}
@@ -0,0 +1,55 @@
+package broadcast
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Sender delivers signed transaction to the network
+type Sender interface {
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+// NonceHolder keeps signed transactions nodes don't know about yet
+type NonceHolder interface {
+	// HeldNonce returns the highest nonce of account transactions held, false if there are none
+	HeldNonce(account common.Address) (uint64, bool)
+}
+
+type privateCtxKey struct{}
+
+// Private marks ctx, so transaction sent with it goes through private sender of the backend
+func Private(ctx context.Context) context.Context {
+	return context.WithValue(ctx, privateCtxKey{}, true)
+}
+
+func isPrivate(ctx context.Context) bool {
+	private, _ := ctx.Value(privateCtxKey{}).(bool)
+	return private
+}
+
+// backend is a contract backend which sends transactions signed by bound contracts
+// through private sender if they are marked private, and publicly otherwise
+type backend struct {
+	bind.ContractBackend
+	private Sender
+}
+
+// NewBackend wraps public backend, so transactions marked private are sent through private sender,
+// public backend is returned as is if there is no private sender
+func NewBackend(public bind.ContractBackend, private Sender) bind.ContractBackend {
+	if private == nil {
+		return public
+	}
+	return &backend{ContractBackend: public, private: private}
+}
+
+func (b *backend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if isPrivate(ctx) {
+		return b.private.SendTransaction(ctx, tx)
+	}
+	return b.ContractBackend.SendTransaction(ctx, tx)
+}
@@ -0,0 +1,97 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	bind.ContractBackend
+	sent []*types.Transaction
+}
+
+func (r *recorder) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	r.sent = append(r.sent, tx)
+	return nil
+}
+
+func TestBackend(t *testing.T) {
+	public := &recorder{}
+	private := &recorder{}
+	backend := NewBackend(public, private)
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil)
+
+	assert.NoError(t, backend.SendTransaction(context.Background(), tx))
+	assert.NoError(t, backend.SendTransaction(Private(context.Background()), tx))
+	assert.Len(t, public.sent, 1)
+	assert.Len(t, private.sent, 1)
+
+	assert.Equal(t, public, NewBackend(public, nil))
+}
+
+func TestRelaySubmit(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	tx := types.NewTransaction(7, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil)
+
+	var request struct {
+		Method string                   `json:"method"`
+		Params []map[string]interface{} `json:"params"`
+	}
+	var signer string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal(body, &request))
+
+		parts := strings.Split(r.Header.Get("X-Flashbots-Signature"), ":")
+		assert.Len(t, parts, 2)
+		message := hexutil.Encode(crypto.Keccak256(body))
+		hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)))
+		signature := hexutil.MustDecode(parts[1])
+		signature[64] -= 27
+		pub, err := crypto.SigToPub(hash, signature)
+		assert.NoError(t, err)
+		signer = crypto.PubkeyToAddress(*pub).String()
+		assert.Equal(t, parts[0], signer)
+
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x"}`))
+	}))
+	defer server.Close()
+
+	relay := NewRelay(http.DefaultClient, server.URL, MethodPrivate, key)
+	assert.NoError(t, relay.Submit(context.Background(), tx, 101, 125))
+	assert.Equal(t, "eth_sendPrivateTransaction", request.Method)
+	assert.Equal(t, "0x7d", request.Params[0]["maxBlockNumber"])
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey).String(), signer)
+
+	relay = NewRelay(http.DefaultClient, server.URL, MethodBundle, key)
+	assert.NoError(t, relay.Submit(context.Background(), tx, 101, 125))
+	assert.Equal(t, "eth_sendBundle", request.Method)
+	assert.Equal(t, "0x65", request.Params[0]["blockNumber"])
+}
+
+func TestRelayError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"bundle rejected"}}`))
+	}))
+	defer server.Close()
+
+	tx := types.NewTransaction(7, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil)
+	err := NewRelay(http.DefaultClient, server.URL, MethodPrivate, nil).Submit(context.Background(), tx, 1, 2)
+	assert.Error(t, err)
+}
@@ -0,0 +1,205 @@
+package broadcast
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/tokend/erc20-withdraw-svc/internal/eth"
+	"github.com/tokend/erc20-withdraw-svc/internal/storage"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	"gitlab.com/distributed_lab/running"
+)
+
+// pending is a transaction submitted to relay and not mined yet
+type pending struct {
+	Raw string `json:"raw"`
+	// Until is a block after which transaction is broadcast publicly
+	Until uint64 `json:"until"`
+}
+
+// PrivateSender submits transactions to relay and broadcasts them publicly if they are not mined within fallback blocks.
+// Submitted transactions are persisted, so fallback happens after restart as well.
+type PrivateSender struct {
+	log    *logan.Entry
+	relay  *Relay
+	client eth.Client
+	blocks uint64
+	file   *storage.File
+
+	mu      sync.Mutex
+	pending map[common.Hash]pending
+}
+
+// NewPrivateSender creates sender persisted in file, loading transactions submitted before restart
+func NewPrivateSender(log *logan.Entry, relay *Relay, client eth.Client, blocks uint64, file *storage.File) (*PrivateSender, error) {
+	txs := make(map[common.Hash]pending)
+	if err := file.Load(&txs); err != nil {
+		return nil, errors.Wrap(err, "failed to load private transactions")
+	}
+
+	return &PrivateSender{
+		log:     log.WithField("service", "private-sender"),
+		relay:   relay,
+		client:  client,
+		blocks:  blocks,
+		file:    file,
+		pending: txs,
+	}, nil
+}
+
+// SendTransaction submits transaction to relay, falling back to public broadcast right away if relay fails
+func (s *PrivateSender) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	head, err := s.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to get head block")
+	}
+	block := head.Number.Uint64()
+
+	raw, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode transaction")
+	}
+	// transaction is persisted before submission, so it is not lost if service stops right after it
+	if err := s.add(tx.Hash(), pending{Raw: hexutil.Encode(raw), Until: block + s.blocks}); err != nil {
+		return err
+	}
+
+	if err := s.relay.Submit(ctx, tx, block+1, block+s.blocks); err != nil {
+		s.log.WithError(err).WithField("tx_hash", tx.Hash().String()).Warn("relay failed, broadcasting transaction publicly")
+		s.remove(tx.Hash())
+		return s.client.SendTransaction(ctx, tx)
+	}
+
+	s.log.WithField("tx_hash", tx.Hash().String()).Info("transaction is submitted to relay")
+	return nil
+}
+
+// Run watches submitted transactions until ctx is cancelled
+func (s *PrivateSender) Run(ctx context.Context) {
+	running.WithBackOff(ctx, s.log, "private-sender", s.check, 5*time.Second, 5*time.Second, time.Minute)
+}
+
+// check drops mined transactions, broadcasts publicly ones relay failed to include in time
+// and resubmits bundles, as bundle targets a single block
+func (s *PrivateSender) check(ctx context.Context) error {
+	s.mu.Lock()
+	txs := make(map[common.Hash]pending, len(s.pending))
+	for hash, tx := range s.pending {
+		txs[hash] = tx
+	}
+	s.mu.Unlock()
+	if len(txs) == 0 {
+		return nil
+	}
+
+	head, err := s.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to get head block")
+	}
+	block := head.Number.Uint64()
+
+	for hash, p := range txs {
+		fields := logan.F{"tx_hash": hash.String()}
+		_, err := s.client.TransactionReceipt(ctx, hash)
+		if err == nil {
+			s.remove(hash)
+			continue
+		}
+		if err != ethereum.NotFound {
+			return errors.Wrap(err, "failed to get transaction receipt", fields)
+		}
+
+		tx, err := decode(p.Raw)
+		if err != nil {
+			s.log.WithError(err).WithFields(fields).Error("failed to decode private transaction, dropping it")
+			s.remove(hash)
+			continue
+		}
+
+		if block < p.Until {
+			if s.relay.Method() == MethodBundle {
+				if err := s.relay.Submit(ctx, tx, block+1, p.Until); err != nil {
+					s.log.WithError(err).WithFields(fields).Warn("failed to resubmit bundle")
+				}
+			}
+			continue
+		}
+
+		s.log.WithFields(fields).Warn("transaction was not included by relay in time, broadcasting it publicly")
+		if err := s.client.SendTransaction(ctx, tx); err != nil {
+			// transaction may have been mined or known to the node already, it won't be retried in either case
+			s.log.WithError(err).WithFields(fields).Warn("failed to broadcast transaction publicly")
+		}
+		s.remove(hash)
+	}
+
+	return nil
+}
+
+// HeldNonce returns the highest nonce of account transactions held by sender until they are mined or broadcast publicly.
+// Nodes don't see these transactions, so their pending nonce doesn't account for them.
+func (s *PrivateSender) HeldNonce(account common.Address) (uint64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var nonce uint64
+	var held bool
+	for hash, p := range s.pending {
+		tx, err := decode(p.Raw)
+		if err != nil {
+			s.log.WithError(err).WithField("tx_hash", hash.String()).Warn("failed to decode private transaction")
+			continue
+		}
+		from, err := types.Sender(types.NewEIP155Signer(tx.ChainId()), tx)
+		if err != nil || from != account {
+			continue
+		}
+		if !held || tx.Nonce() > nonce {
+			nonce, held = tx.Nonce(), true
+		}
+	}
+	return nonce, held
+}
+
+func (s *PrivateSender) add(hash common.Hash, tx pending) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending[hash] = tx
+	if err := s.file.Save(s.pending); err != nil {
+		delete(s.pending, hash)
+		return errors.Wrap(err, "failed to persist private transaction")
+	}
+	return nil
+}
+
+func (s *PrivateSender) remove(hash common.Hash) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, ok := s.pending[hash]
+	if !ok {
+		return
+	}
+	delete(s.pending, hash)
+	if err := s.file.Save(s.pending); err != nil {
+		s.pending[hash] = tx
+		s.log.WithError(err).WithField("tx_hash", hash.String()).Error("failed to persist private transactions")
+	}
+}
+
+func decode(raw string) (*types.Transaction, error) {
+	bb, err := hexutil.Decode(raw)
+	if err != nil {
+		return nil, err
+	}
+	tx := new(types.Transaction)
+	return tx, rlp.DecodeBytes(bb, tx)
+}
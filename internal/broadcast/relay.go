@@ -0,0 +1,149 @@
+package broadcast
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	// MethodPrivate submits transaction with `eth_sendPrivateTransaction`, relay keeps it until max block
+	MethodPrivate = "private"
+	// MethodBundle submits single transaction bundle with `eth_sendBundle` for the next block only
+	MethodBundle = "bundle"
+)
+
+// Relay submits signed transactions to private relay, bypassing public mempool
+type Relay struct {
+	endpoint string
+	method   string
+	// authKey signs request bodies, relay identifies searcher by it
+	authKey *ecdsa.PrivateKey
+	client  *http.Client
+}
+
+// NewRelay creates client of relay served at endpoint, authKey is optional
+func NewRelay(client *http.Client, endpoint, method string, authKey *ecdsa.PrivateKey) *Relay {
+	return &Relay{
+		endpoint: endpoint,
+		method:   method,
+		authKey:  authKey,
+		client:   client,
+	}
+}
+
+// Method returns submission method of the relay
+func (r *Relay) Method() string {
+	return r.method
+}
+
+// Submit submits transaction to be included in block, not later than maxBlock for private transactions
+func (r *Relay) Submit(ctx context.Context, tx *types.Transaction, block, maxBlock uint64) error {
+	raw, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode transaction")
+	}
+
+	var method string
+	var params interface{}
+	switch r.method {
+	case MethodBundle:
+		method = "eth_sendBundle"
+		params = map[string]interface{}{
+			"txs":         []string{hexutil.Encode(raw)},
+			"blockNumber": hexutil.EncodeUint64(block),
+		}
+	default:
+		method = "eth_sendPrivateTransaction"
+		params = map[string]interface{}{
+			"tx":             hexutil.Encode(raw),
+			"maxBlockNumber": hexutil.EncodeUint64(maxBlock),
+		}
+	}
+
+	return errors.Wrap(r.call(ctx, method, params), "failed to submit transaction to relay", logan.F{
+		"tx_hash": tx.Hash().String(),
+	})
+}
+
+func (r *Relay) call(ctx context.Context, method string, params interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  []interface{}{params},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal request")
+	}
+
+	request, err := http.NewRequest(http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to prepare request")
+	}
+	request = request.WithContext(ctx)
+	request.Header.Set("Content-Type", "application/json")
+	if r.authKey != nil {
+		signature, err := r.sign(body)
+		if err != nil {
+			return err
+		}
+		request.Header.Set("X-Flashbots-Signature", signature)
+	}
+
+	response, err := r.client.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform http request")
+	}
+	defer response.Body.Close()
+
+	respBB, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read response body")
+	}
+	if response.StatusCode != http.StatusOK {
+		return errors.From(errors.New("relay responded with error status"), logan.F{
+			"status_code": response.StatusCode,
+			"body":        string(respBB),
+		})
+	}
+
+	var result struct {
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(respBB, &result); err != nil {
+		return errors.Wrap(err, "failed to unmarshal response")
+	}
+	if result.Error != nil {
+		return errors.From(errors.New(result.Error.Message), logan.F{"code": result.Error.Code})
+	}
+
+	return nil
+}
+
+// sign returns `address:signature` of body hash, signed as personal message
+func (r *Relay) sign(body []byte) (string, error) {
+	message := hexutil.Encode(crypto.Keccak256(body))
+	hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)))
+	signature, err := crypto.Sign(hash, r.authKey)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to sign relay request")
+	}
+	signature[64] += 27
+
+	return crypto.PubkeyToAddress(r.authKey.PublicKey).String() + ":" + hexutil.Encode(signature), nil
+}
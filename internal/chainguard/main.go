@@ -0,0 +1,27 @@
+package chainguard
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/tokend/erc20-withdraw-svc/internal/config"
+	"github.com/tokend/erc20-withdraw-svc/internal/ethpool"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+var (
+	ErrChainIDMismatch = ethpool.ErrChainIDMismatch
+	ErrGenesisMismatch = ethpool.ErrGenesisMismatch
+)
+
+// Check ensures node of the chain is on the expected network, returns chain id reported by node.
+// Chain id and genesis hash are checked only if they are pinned in config. Pool of nodes checks every node
+// on connect and keeps mismatching ones out, so mismatch is reported here once none of the nodes is usable.
+func Check(ctx context.Context, chain config.Chain) (*big.Int, error) {
+	chainID, err := chain.Pin().Check(ctx, chain.Client)
+	if err != nil {
+		return nil, errors.Wrap(err, "chain check failed", logan.F{"chain": chain.Name})
+	}
+	return chainID, nil
+}
@@ -3,10 +3,18 @@ package cli
 import (
 	"context"
 	"fmt"
-	"gitlab.com/distributed_lab/kit/kv"
-	"gitlab.com/distributed_lab/logan/v3"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
 	"github.com/tokend/erc20-withdraw-svc/internal/config"
+	"github.com/tokend/erc20-withdraw-svc/internal/hold"
+	"github.com/tokend/erc20-withdraw-svc/internal/safe"
+	"github.com/tokend/erc20-withdraw-svc/internal/services/admin"
 	"github.com/tokend/erc20-withdraw-svc/internal/services/withdrawer"
+	"github.com/tokend/erc20-withdraw-svc/internal/timelock"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3"
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
 )
 
@@ -24,6 +32,37 @@ func Run(args []string) bool {
 	withdraw := runCmd.Command("withdraw", "run withdraw service")
 	versionCmd := app.Command("version", "service revision")
 
+	heldCmd := app.Command("held", "manage withdrawals held for manual review")
+	heldEndpoint := heldCmd.Flag("endpoint", "admin API endpoint, defaults to one built from admin.address").String()
+	heldToken := heldCmd.Flag("token", "reviewer token").Envar("ADMIN_TOKEN").Required().String()
+	heldList := heldCmd.Command("list", "list held withdrawals")
+	heldApprove := heldCmd.Command("approve", "approve held withdrawal")
+	approveID := heldApprove.Arg("id", "withdraw request id").Required().String()
+	approveComment := heldApprove.Flag("comment", "decision comment").String()
+	heldReject := heldCmd.Command("reject", "reject held withdrawal")
+	rejectID := heldReject.Arg("id", "withdraw request id").Required().String()
+	rejectComment := heldReject.Flag("comment", "decision comment").String()
+
+	timelockCmd := app.Command("timelock", "manage time locked withdrawals")
+	timelockEndpoint := timelockCmd.Flag("endpoint", "admin API endpoint, defaults to one built from admin.address").String()
+	timelockToken := timelockCmd.Flag("token", "reviewer token").Envar("ADMIN_TOKEN").Required().String()
+	timelockList := timelockCmd.Command("list", "list time locked withdrawals")
+	timelockCancel := timelockCmd.Command("cancel", "cancel time locked withdrawal")
+	cancelID := timelockCancel.Arg("id", "withdraw request id").Required().String()
+	cancelComment := timelockCancel.Flag("comment", "cancellation comment").String()
+
+	safeCmd := app.Command("safe", "manage withdrawals sent from safe")
+	safeEndpoint := safeCmd.Flag("endpoint", "admin API endpoint, defaults to one built from admin.address").String()
+	safeToken := safeCmd.Flag("token", "reviewer token").Envar("ADMIN_TOKEN").Required().String()
+	safeList := safeCmd.Command("list", "list proposed safe transactions")
+	safeConfirm := safeCmd.Command("confirm", "submit safe owner confirmation")
+	confirmID := safeConfirm.Arg("id", "withdraw request id").Required().String()
+	confirmHash := safeConfirm.Flag("hash", "confirmed safe transaction hash").Required().String()
+	confirmSignature := safeConfirm.Flag("signature", "owner signature of safe transaction hash").Required().String()
+	safeDiscard := safeCmd.Command("discard", "discard safe transaction, later ones are proposed again to take its nonce")
+	discardID := safeDiscard.Arg("id", "withdraw request id").Required().String()
+	discardComment := safeDiscard.Flag("comment", "discard comment").String()
+
 	cfg := config.NewConfig(kv.MustFromEnv())
 	log = cfg.Log()
 
@@ -32,12 +71,78 @@ func Run(args []string) bool {
 		log.WithError(err).Error("failed to parse arguments")
 	}
 
+	adminClient := func(endpoint, token string) *admin.Client {
+		if endpoint == "" {
+			endpoint = "http://" + cfg.AdminConfig().Address
+		}
+		return admin.NewClient(http.DefaultClient, endpoint, token)
+	}
+
 	switch cmd {
 	case withdraw.FullCommand():
 		svc := withdrawer.New(cfg)
 		svc.Run(context.Background())
 	case versionCmd.FullCommand():
 		fmt.Println(config.ERC20WithdrawVersion)
+	case heldList.FullCommand():
+		requests, err := adminClient(*heldEndpoint, *heldToken).List()
+		if err != nil {
+			log.WithError(err).Error("failed to list held withdrawals")
+			return false
+		}
+		printHeld(requests...)
+	case heldApprove.FullCommand():
+		request, err := adminClient(*heldEndpoint, *heldToken).Decide(*approveID, true, *approveComment)
+		if err != nil {
+			log.WithError(err).Error("failed to approve held withdrawal")
+			return false
+		}
+		printHeld(*request)
+	case heldReject.FullCommand():
+		request, err := adminClient(*heldEndpoint, *heldToken).Decide(*rejectID, false, *rejectComment)
+		if err != nil {
+			log.WithError(err).Error("failed to reject held withdrawal")
+			return false
+		}
+		printHeld(*request)
+	case timelockList.FullCommand():
+		locks, err := adminClient(*timelockEndpoint, *timelockToken).ListTimelocks()
+		if err != nil {
+			log.WithError(err).Error("failed to list time locked withdrawals")
+			return false
+		}
+		printTimelocks(locks...)
+	case timelockCancel.FullCommand():
+		lock, err := adminClient(*timelockEndpoint, *timelockToken).CancelTimelock(*cancelID, *cancelComment)
+		if err != nil {
+			log.WithError(err).Error("failed to cancel time locked withdrawal")
+			return false
+		}
+		printTimelocks(*lock)
+	case safeList.FullCommand():
+		proposals, err := adminClient(*safeEndpoint, *safeToken).ListSafe()
+		if err != nil {
+			log.WithError(err).Error("failed to list safe transactions")
+			return false
+		}
+		printProposals(proposals...)
+	case safeConfirm.FullCommand():
+		proposal, err := adminClient(*safeEndpoint, *safeToken).ConfirmSafe(*confirmID, admin.Confirmation{
+			SafeTxHash: *confirmHash,
+			Signature:  *confirmSignature,
+		})
+		if err != nil {
+			log.WithError(err).Error("failed to confirm safe transaction")
+			return false
+		}
+		printProposals(*proposal)
+	case safeDiscard.FullCommand():
+		proposal, err := adminClient(*safeEndpoint, *safeToken).DiscardSafe(*discardID, *discardComment)
+		if err != nil {
+			log.WithError(err).Error("failed to discard safe transaction")
+			return false
+		}
+		printProposals(*proposal)
 	default:
 		log.Errorf("unknown command %s", cmd)
 		return false
@@ -45,3 +150,33 @@ func Run(args []string) bool {
 
 	return true
 }
+
+func printHeld(requests ...hold.Request) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tASSET\tAMOUNT\tADDRESS\tSTATE\tREASON\tHELD AT\tDECIDED BY")
+	for _, r := range requests {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.ID, r.Asset, r.Amount, r.Address, r.State, r.Reason, r.HeldAt.Format("2006-01-02 15:04:05"), r.DecidedBy)
+	}
+	w.Flush()
+}
+
+func printTimelocks(locks ...timelock.Lock) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tASSET\tAMOUNT\tADDRESS\tSTATE\tSEND AT\tCANCELLED BY")
+	for _, l := range locks {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			l.ID, l.Asset, l.Amount, l.Address, l.State, l.SendAt.Format("2006-01-02 15:04:05"), l.CancelledBy)
+	}
+	w.Flush()
+}
+
+func printProposals(proposals ...safe.Proposal) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tASSET\tAMOUNT\tADDRESS\tSAFE\tNONCE\tSAFE TX HASH\tCONFIRMATIONS")
+	for _, p := range proposals {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%d\n",
+			p.ID, p.Asset, p.Amount, p.Address, p.Safe, p.Nonce, p.SafeTxHash, len(p.Signatures))
+	}
+	w.Flush()
+}
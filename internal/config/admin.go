@@ -0,0 +1,50 @@
+package config
+
+import (
+	"github.com/spf13/cast"
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type AdminConfig struct {
+	// Admin API is disabled if address is not set
+	Address string `fig:"address"`
+	// Bearer tokens by reviewer identity
+	Reviewers map[string]string `fig:"-"`
+}
+
+func (c *config) AdminConfig() AdminConfig {
+	c.once.Do(func() interface{} {
+		result := AdminConfig{
+			Reviewers: make(map[string]string),
+		}
+
+		raw := kv.MustGetStringMap(c.getter, "admin")
+		err := figure.
+			Out(&result).
+			With(figure.BaseHooks).
+			From(raw).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out admin"))
+		}
+
+		// admin API may be disabled, so there may be no reviewers
+		if reviewers, ok := raw["reviewers"]; ok {
+			result.Reviewers, err = cast.ToStringMapStringE(reviewers)
+			if err != nil {
+				panic(errors.Wrap(err, "failed to figure out admin reviewers"))
+			}
+		}
+		for reviewer, token := range result.Reviewers {
+			if token == "" {
+				panic(errors.Errorf("empty token of reviewer %s", reviewer))
+			}
+		}
+
+		c.adminConfig = result
+		return nil
+	})
+	return c.adminConfig
+}
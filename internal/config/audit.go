@@ -0,0 +1,31 @@
+package config
+
+import (
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type AuditConfig struct {
+	// Audit events are appended to the file as JSON lines, if path is set
+	Path string `fig:"path"`
+}
+
+func (c *config) AuditConfig() AuditConfig {
+	c.once.Do(func() interface{} {
+		var result AuditConfig
+
+		err := figure.
+			Out(&result).
+			With(figure.BaseHooks).
+			From(kv.MustGetStringMap(c.getter, "audit")).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out audit"))
+		}
+
+		c.auditConfig = result
+		return nil
+	})
+	return c.auditConfig
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// DefaultBatchSize is a maximal number of withdrawals in a batch if not configured
+const DefaultBatchSize = 100
+
+// AssetBatch collects withdrawals for Window and sends them in a single multisend contract call
+type AssetBatch struct {
+	Contract string        `fig:"contract,required"`
+	Method   string        `fig:"method"`
+	Window   time.Duration `fig:"window,required"`
+	MaxSize  int           `fig:"max_size"`
+}
+
+func (c AssetBatch) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Contract, validation.NewStringRule(common.IsHexAddress, "must be valid contract address")),
+		validation.Field(&c.Window, validation.Required, validation.Min(time.Duration(0))),
+		validation.Field(&c.MaxSize, validation.Min(1)),
+	)
+}
+
+type BatchConfig struct {
+	// Batching by asset code, withdrawals of assets not listed here are sent one by one
+	Assets map[string]AssetBatch
+}
+
+func (c *config) BatchConfig() BatchConfig {
+	c.once.Do(func() interface{} {
+		result := BatchConfig{
+			Assets: make(map[string]AssetBatch),
+		}
+
+		err := figureAssets(kv.MustGetStringMap(c.getter, "batch"), func(code string, values map[string]interface{}) error {
+			batch := AssetBatch{
+				MaxSize: DefaultBatchSize,
+			}
+			err := figure.
+				Out(&batch).
+				With(figure.BaseHooks).
+				From(values).
+				Please()
+			if err != nil {
+				return err
+			}
+			if err := batch.Validate(); err != nil {
+				return err
+			}
+
+			result.Assets[code] = batch
+			return nil
+		})
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out batch"))
+		}
+
+		c.batchConfig = result
+		return nil
+	})
+	return c.batchConfig
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"io/ioutil"
+
+	"github.com/ethereum/go-ethereum/common"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/tokend/erc20-withdraw-svc/internal/token"
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// AssetBridge is a bridge contract ERC20 asset in `bridge` mode is withdrawn through
+type AssetBridge struct {
+	Address string `fig:"address,required"`
+	// ABIPath is a path to JSON ABI of the bridge contract
+	ABIPath string `fig:"abi_path,required"`
+	Method  string `fig:"method"`
+	Event   string `fig:"event"`
+}
+
+func (c AssetBridge) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Address, validation.NewStringRule(common.IsHexAddress, "must be valid contract address")),
+	)
+}
+
+type BridgeConfig struct {
+	// Bridge contracts by asset code, ABI is loaded from the file
+	Assets map[string]token.Bridge
+}
+
+func (c *config) BridgeConfig() BridgeConfig {
+	c.once.Do(func() interface{} {
+		result := BridgeConfig{
+			Assets: make(map[string]token.Bridge),
+		}
+
+		err := figureAssets(kv.MustGetStringMap(c.getter, "bridge"), func(code string, values map[string]interface{}) error {
+			var bridge AssetBridge
+			err := figure.
+				Out(&bridge).
+				With(figure.BaseHooks).
+				From(values).
+				Please()
+			if err != nil {
+				return err
+			}
+			if err := bridge.Validate(); err != nil {
+				return err
+			}
+
+			abi, err := ioutil.ReadFile(bridge.ABIPath)
+			if err != nil {
+				return errors.Wrap(err, "failed to read bridge ABI")
+			}
+
+			result.Assets[code] = token.Bridge{
+				Address: common.HexToAddress(bridge.Address),
+				ABI:     string(abi),
+				Method:  bridge.Method,
+				Event:   bridge.Event,
+			}
+			return nil
+		})
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out bridge"))
+		}
+
+		c.bridgeConfig = result
+		return nil
+	})
+	return c.bridgeConfig
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"math/big"
+	"regexp"
+
+	"github.com/ethereum/go-ethereum/common"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/spf13/cast"
+	"github.com/tokend/erc20-withdraw-svc/internal/eth"
+	"github.com/tokend/erc20-withdraw-svc/internal/ethpool"
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// DefaultChain is a name of chain configured by `rpc` and `transfer`, it is used by assets which don't specify one
+const DefaultChain = "default"
+
+var genesisHashRegexp = regexp.MustCompile("^0x[0-9a-fA-F]{64}$")
+
+// Chain is an EVM network with its own node, hot wallet and gas policy
+type Chain struct {
+	Name     string
+	Client   eth.Client
+	Transfer TransferConfig
+	// Relay is set if transactions can be submitted privately
+	Relay *RelayConfig
+	// Quorum is a number of nodes which must agree on transaction before withdrawal is marked done, 0 disables the check
+	Quorum int
+	ChainPin
+}
+
+// ChainPin pins network node must be on, nothing is checked if values are not set
+type ChainPin struct {
+	ExpectedChainID *big.Int `fig:"expected_chain_id"`
+	GenesisHash     string   `fig:"genesis_hash"`
+}
+
+func (p ChainPin) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.GenesisHash, validation.Match(genesisHashRegexp)),
+	)
+}
+
+// Pin returns pin nodes of the chain are checked against on connect
+func (p ChainPin) Pin() ethpool.Pin {
+	result := ethpool.Pin{ChainID: p.ExpectedChainID}
+	if p.GenesisHash != "" {
+		result.Genesis = common.HexToHash(p.GenesisHash)
+	}
+	return result
+}
+
+func (c *config) Chains() map[string]Chain {
+	// chains are dialed only once, so value is cached in separate once
+	return c.chainsOnce.Do(func() interface{} {
+		result := make(map[string]Chain)
+		if rpc := kv.MustGetStringMap(c.getter, "rpc"); len(rpc) > 0 {
+			pin, err := figureChainPin(rpc)
+			if err != nil {
+				panic(errors.Wrap(err, "failed to figure out rpc", logan.F{"chain": DefaultChain}))
+			}
+			relay, err := figureRelay(rpc)
+			if err != nil {
+				panic(errors.Wrap(err, "failed to figure out rpc", logan.F{"chain": DefaultChain}))
+			}
+			client := c.EthClient()
+			quorum, err := figureQuorum(rpc, client)
+			if err != nil {
+				panic(errors.Wrap(err, "failed to figure out rpc", logan.F{"chain": DefaultChain}))
+			}
+			result[DefaultChain] = Chain{
+				Name:     DefaultChain,
+				Client:   client,
+				Transfer: c.TransferConfig(),
+				Relay:    relay,
+				Quorum:   quorum,
+				ChainPin: *pin,
+			}
+		}
+
+		for name, rawValues := range kv.MustGetStringMap(c.getter, "chains") {
+			if _, ok := result[name]; ok {
+				panic(errors.From(errors.New("chain is already defined by rpc config"), logan.F{"chain": name}))
+			}
+
+			chain, err := figureChain(c.Log(), name, rawValues)
+			if err != nil {
+				panic(errors.Wrap(err, "failed to figure out chain", logan.F{"chain": name}))
+			}
+			result[name] = *chain
+		}
+
+		return result
+	}).(map[string]Chain)
+}
+
+func figureChain(log *logan.Entry, name string, rawValues interface{}) (*Chain, error) {
+	values, err := cast.ToStringMapE(rawValues)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse chain")
+	}
+
+	var transfer TransferConfig
+	err = figure.
+		Out(&transfer).
+		With(figure.BaseHooks).
+		From(values).
+		Please()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to figure out transfer")
+	}
+
+	pin, err := figureChainPin(values)
+	if err != nil {
+		return nil, err
+	}
+
+	relay, err := figureRelay(values)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := dialRPC(log.WithField("chain", name), values, pin.Pin())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to figure out rpc")
+	}
+
+	quorum, err := figureQuorum(values, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Chain{
+		Name:     name,
+		Client:   client,
+		Transfer: transfer,
+		Relay:    relay,
+		Quorum:   quorum,
+		ChainPin: *pin,
+	}, nil
+}
+
+func figureChainPin(values map[string]interface{}) (*ChainPin, error) {
+	var pin ChainPin
+	err := figure.
+		Out(&pin).
+		With(figure.BaseHooks).
+		From(values).
+		Please()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to figure out chain pin")
+	}
+	if err := pin.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid chain pin")
+	}
+
+	return &pin, nil
+}
+
+func figureQuorum(values map[string]interface{}, client *ethpool.Client) (int, error) {
+	var config struct {
+		Quorum int `fig:"quorum"`
+	}
+	err := figure.
+		Out(&config).
+		With(figure.BaseHooks).
+		From(values).
+		Please()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to figure out quorum")
+	}
+	if config.Quorum < 0 || config.Quorum > client.Size() {
+		return 0, errors.From(errors.New("quorum must not exceed number of endpoints"), logan.F{
+			"quorum":    config.Quorum,
+			"endpoints": client.Size(),
+		})
+	}
+
+	return config.Quorum, nil
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// getter is a config source backed by map, missing section is empty like in file backed one
+type getter map[string]map[string]interface{}
+
+func (g getter) GetStringMap(key string) (map[string]interface{}, error) {
+	if section, ok := g[key]; ok {
+		return section, nil
+	}
+	return map[string]interface{}{}, nil
+}
+
+func TestConfig_Chains(t *testing.T) {
+	t.Run("chains only", func(t *testing.T) {
+		cfg := NewConfig(getter{
+			"log": {"disable_sentry": true},
+			"chains": {
+				"polygon": map[string]interface{}{
+					// node is unreachable, pool keeps retrying it in background
+					"endpoint":      "http://127.0.0.1:1",
+					"seed":          "0x0000000000000000000000000000000000000000000000000000000000000001",
+					"address":       "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf",
+					"confirmations": 3,
+				},
+			},
+		})
+
+		var chains map[string]Chain
+		assert.NotPanics(t, func() {
+			chains = cfg.Chains()
+		})
+		assert.Len(t, chains, 1)
+		if assert.Contains(t, chains, "polygon") {
+			assert.Equal(t, "polygon", chains["polygon"].Name)
+			assert.EqualValues(t, 3, chains["polygon"].Transfer.Confirmations)
+		}
+		assert.NotContains(t, chains, DefaultChain)
+	})
+}
@@ -1,45 +1,39 @@
 package config
 
 import (
-	"fmt"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/tokend/erc20-withdraw-svc/internal/ethpool"
 	"gitlab.com/distributed_lab/figure"
 	"gitlab.com/distributed_lab/kit/comfig"
 	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3"
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
 type Ether interface {
-	EthClient() *ethclient.Client
+	EthClient() *ethpool.Client
 }
 
 type ether struct {
 	getter kv.Getter
+	logger comfig.Logger
 	once   comfig.Once
-	value  *ethclient.Client
+	value  *ethpool.Client
 }
 
-func NewEther(getter kv.Getter) Ether {
-	return &ether{getter: getter}
+func NewEther(getter kv.Getter, logger comfig.Logger) Ether {
+	return &ether{getter: getter, logger: logger}
 }
 
-func (h *ether) EthClient() *ethclient.Client {
+func (h *ether) EthClient() *ethpool.Client {
 	h.once.Do(func() interface{} {
-		var config struct {
-			Endpoint string `fig:"endpoint,required"`
-		}
-
-		err := figure.
-			Out(&config).
-			With(figure.BaseHooks).
-			From(kv.MustGetStringMap(h.getter, "rpc")).
-			Please()
+		values := kv.MustGetStringMap(h.getter, "rpc")
+		pin, err := figureChainPin(values)
 		if err != nil {
 			panic(errors.Wrap(err, "failed to figure out rpc"))
 		}
-		eth, err := ethclient.Dial(config.Endpoint)
+		eth, err := dialRPC(h.logger.Log(), values, pin.Pin())
 		if err != nil {
-			panic(fmt.Sprintf("failed to dial %s", config.Endpoint))
+			panic(errors.Wrap(err, "failed to figure out rpc"))
 		}
 
 		h.value = eth
@@ -48,3 +42,39 @@ func (h *ether) EthClient() *ethclient.Client {
 
 	return h.value
 }
+
+// dialRPC connects to nodes listed by `endpoint` and `endpoints`, unreachable nodes are dialed again in background
+// and nodes on network other than pinned one are not used
+func dialRPC(log *logan.Entry, values map[string]interface{}, pin ethpool.Pin) (*ethpool.Client, error) {
+	config := struct {
+		Endpoint  string   `fig:"endpoint"`
+		Endpoints []string `fig:"endpoints"`
+		MaxLag    uint64   `fig:"max_lag"`
+	}{
+		MaxLag: ethpool.DefaultMaxLag,
+	}
+
+	err := figure.
+		Out(&config).
+		With(figure.BaseHooks).
+		From(values).
+		Please()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to figure out endpoints")
+	}
+
+	endpoints := config.Endpoints
+	if config.Endpoint != "" {
+		endpoints = append([]string{config.Endpoint}, endpoints...)
+	}
+	if len(endpoints) == 0 {
+		return nil, errors.New("either endpoint or endpoints must be set")
+	}
+
+	client, err := ethpool.Dial(log, endpoints, config.MaxLag, pin)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to dial rpc", logan.F{"endpoints": endpoints})
+	}
+
+	return client, nil
+}
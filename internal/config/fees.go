@@ -0,0 +1,79 @@
+package config
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation"
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+const (
+	// RateSourceFile reads ETH price in whole tokens from a local file
+	RateSourceFile = "file"
+	// RateSourceKeyValue reads ETH price in whole tokens from TokenD key value entry
+	RateSourceKeyValue = "key_value"
+)
+
+// AssetFee is a fee deducted from withdrawn amount before it is sent,
+// flat part is in TokenD amount, gas part is estimated gas cost converted through rate source
+type AssetFee struct {
+	Flat       regources.Amount `fig:"flat"`
+	RateSource string           `fig:"rate_source"`
+	RatePath   string           `fig:"rate_path"`
+	RateKey    string           `fig:"rate_key"`
+}
+
+func (c AssetFee) Validate() error {
+	var pathRules, keyRules []validation.Rule
+	switch c.RateSource {
+	case RateSourceFile:
+		pathRules = append(pathRules, validation.Required)
+	case RateSourceKeyValue:
+		keyRules = append(keyRules, validation.Required)
+	}
+
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.RateSource, validation.In(RateSourceFile, RateSourceKeyValue)),
+		validation.Field(&c.RatePath, pathRules...),
+		validation.Field(&c.RateKey, keyRules...),
+	)
+}
+
+type FeesConfig struct {
+	// Fees by asset code, withdrawals of assets not listed here are free
+	Assets map[string]AssetFee
+}
+
+func (c *config) FeesConfig() FeesConfig {
+	c.once.Do(func() interface{} {
+		result := FeesConfig{
+			Assets: make(map[string]AssetFee),
+		}
+
+		err := figureAssets(kv.MustGetStringMap(c.getter, "fees"), func(code string, values map[string]interface{}) error {
+			var fee AssetFee
+			err := figure.
+				Out(&fee).
+				With(figure.BaseHooks, amountHooks).
+				From(values).
+				Please()
+			if err != nil {
+				return err
+			}
+			if err := fee.Validate(); err != nil {
+				return err
+			}
+
+			result.Assets[code] = fee
+			return nil
+		})
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out fees"))
+		}
+
+		c.feesConfig = result
+		return nil
+	})
+	return c.feesConfig
+}
@@ -1,25 +1,35 @@
 package config
 
 import (
+	"net/http"
+	"net/url"
+	"time"
+
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/client"
+	"github.com/tokend/erc20-withdraw-svc/internal/horizon/ratelimit"
+	"github.com/tokend/erc20-withdraw-svc/internal/horizon/submit"
 	"gitlab.com/distributed_lab/figure"
 	"gitlab.com/distributed_lab/kit/comfig"
 	"gitlab.com/distributed_lab/kit/kv"
 	"gitlab.com/distributed_lab/logan/v3/errors"
 	"gitlab.com/tokend/keypair"
 	"gitlab.com/tokend/keypair/figurekeypair"
-	"net/http"
-	"net/url"
 )
 
 type Horizoner interface {
 	Horizon() *client.Client
+	// Submitter submits transactions with its own timeout, as core may take a while to apply them
+	Submitter() submit.Interface
+	// HorizonLimiter limits requests of all Horizon clients
+	HorizonLimiter() *ratelimit.Limiter
 }
 
 type horizoner struct {
-	getter kv.Getter
-	once   comfig.Once
-	value  *client.Client
+	getter  kv.Getter
+	once    comfig.Once
+	value   *client.Client
+	submit  *client.Client
+	limiter *ratelimit.Limiter
 }
 
 func NewHorizoner(getter kv.Getter) Horizoner {
@@ -28,9 +38,31 @@ func NewHorizoner(getter kv.Getter) Horizoner {
 
 func (h *horizoner) Horizon() *client.Client {
 	h.once.Do(func() interface{} {
-		var config struct {
-			Endpoint *url.URL     `fig:"endpoint,required"`
-			Signer   keypair.Full `fig:"signer,required"`
+		config := struct {
+			Endpoint      *url.URL      `fig:"endpoint,required"`
+			Signer        keypair.Full  `fig:"signer,required"`
+			Timeout       time.Duration `fig:"timeout"`
+			SubmitTimeout time.Duration `fig:"submit_timeout"`
+			// transient failures are retried within timeout
+			RetryAttempts   int           `fig:"retry_attempts"`
+			RetryMinBackoff time.Duration `fig:"retry_min_backoff"`
+			RetryMaxBackoff time.Duration `fig:"retry_max_backoff"`
+			// budgets of rate limiter shared by all workers
+			ReadRate    float64       `fig:"read_rate"`
+			ReadBurst   int           `fig:"read_burst"`
+			SubmitRate  float64       `fig:"submit_rate"`
+			SubmitBurst int           `fig:"submit_burst"`
+			MaxWait     time.Duration `fig:"rate_limit_max_wait"`
+		}{
+			Timeout:         30 * time.Second,
+			SubmitTimeout:   2 * time.Minute,
+			RetryAttempts:   5,
+			RetryMinBackoff: 500 * time.Millisecond,
+			RetryMaxBackoff: 10 * time.Second,
+			ReadRate:        ratelimit.DefaultReads.Rate,
+			ReadBurst:       ratelimit.DefaultReads.Burst,
+			SubmitRate:      ratelimit.DefaultSubmits.Rate,
+			SubmitBurst:     ratelimit.DefaultSubmits.Burst,
 		}
 
 		err := figure.
@@ -41,15 +73,45 @@ func (h *horizoner) Horizon() *client.Client {
 		if err != nil {
 			panic(errors.Wrap(err, "failed to figure out horizon"))
 		}
+		if config.Timeout < 0 || config.SubmitTimeout < 0 {
+			panic(errors.New("horizon timeouts must not be negative"))
+		}
+		if config.RetryMinBackoff <= 0 || config.RetryMaxBackoff < config.RetryMinBackoff {
+			panic(errors.New("horizon retry backoff must be positive and not exceed max backoff"))
+		}
 
-		hrz := client.New(http.DefaultClient, config.Endpoint)
+		if config.ReadRate <= 0 || config.SubmitRate <= 0 || config.ReadBurst < 1 || config.SubmitBurst < 1 {
+			panic(errors.New("horizon rate limits must be positive"))
+		}
+
+		h.limiter = ratelimit.New(
+			ratelimit.Budget{Rate: config.ReadRate, Burst: config.ReadBurst, MaxWait: config.MaxWait},
+			ratelimit.Budget{Rate: config.SubmitRate, Burst: config.SubmitBurst, MaxWait: config.MaxWait},
+		)
+		hrz := client.New(http.DefaultClient, config.Endpoint).WithLimiter(h.limiter).WithRetry(client.Backoff{
+			Attempts: config.RetryAttempts,
+			Min:      config.RetryMinBackoff,
+			Max:      config.RetryMaxBackoff,
+		})
 		if config.Signer != nil {
 			hrz = hrz.WithSigner(config.Signer)
 		}
 
-		h.value = hrz
-		return nil
+		h.value = hrz.WithTimeout(config.Timeout)
+		h.submit = hrz.WithTimeout(config.SubmitTimeout)
+		// clients are cached, so limiter budgets are shared by all workers
+		return h.value
 	})
 
 	return h.value
 }
+
+func (h *horizoner) Submitter() submit.Interface {
+	h.Horizon()
+	return submit.New(h.submit)
+}
+
+func (h *horizoner) HorizonLimiter() *ratelimit.Limiter {
+	h.Horizon()
+	return h.limiter
+}
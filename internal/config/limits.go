@@ -0,0 +1,143 @@
+package config
+
+import (
+	"reflect"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/spf13/cast"
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	"gitlab.com/tokend/go/amount"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+const (
+	//LimitsPolicyDefer leaves request pending until it fits into rolling limits
+	LimitsPolicyDefer = "defer"
+	//LimitsPolicyHold holds request for manual review once it exceeds rolling limits
+	LimitsPolicyHold = "hold"
+)
+
+var amountHooks = figure.Hooks{
+	"regources.Amount": func(value interface{}) (reflect.Value, error) {
+		raw, err := cast.ToStringE(value)
+		if err != nil {
+			return reflect.Value{}, errors.Wrap(err, "failed to parse amount")
+		}
+		result, err := amount.ParseU(raw)
+		if err != nil {
+			return reflect.Value{}, errors.Wrap(err, "failed to parse amount")
+		}
+		return reflect.ValueOf(regources.Amount(result)), nil
+	},
+}
+
+// figureAssets calls figure for each entry of per asset config stored by `assets` key
+func figureAssets(raw map[string]interface{}, figureAsset func(code string, values map[string]interface{}) error) error {
+	// section is optional, no assets are configured if it is omitted
+	if raw["assets"] == nil {
+		return nil
+	}
+	assets, err := cast.ToStringMapE(raw["assets"])
+	if err != nil {
+		return errors.Wrap(err, "failed to parse assets")
+	}
+
+	for code, rawValues := range assets {
+		values, err := cast.ToStringMapE(rawValues)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse asset", logan.F{"asset": code})
+		}
+		if err := figureAsset(code, values); err != nil {
+			return errors.Wrap(err, "failed to figure out asset", logan.F{"asset": code})
+		}
+	}
+
+	return nil
+}
+
+// AssetLimits are withdrawal limits of a single asset in TokenD amounts, zero value means no limit
+type AssetLimits struct {
+	// Withdrawal exceeding this amount is always held for manual review
+	MaxAmount regources.Amount `fig:"max_amount"`
+	// Rolling totals over Window
+	Total          regources.Amount `fig:"total"`
+	PerDestination regources.Amount `fig:"per_destination"`
+	PerRequestor   regources.Amount `fig:"per_requestor"`
+	Window         time.Duration    `fig:"window"`
+}
+
+type LimitsConfig struct {
+	Policy string `fig:"policy"`
+	// Limits by asset code, assets not listed here are not limited
+	Assets map[string]AssetLimits `fig:"-"`
+}
+
+func (c LimitsConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Policy, validation.Required, validation.In(LimitsPolicyDefer, LimitsPolicyHold)),
+	)
+}
+
+// MaxWindow returns the longest rolling window among all assets
+func (c LimitsConfig) MaxWindow() time.Duration {
+	var result time.Duration
+	for _, limits := range c.Assets {
+		if limits.Window > result {
+			result = limits.Window
+		}
+	}
+	return result
+}
+
+func (c *config) LimitsConfig() LimitsConfig {
+	c.once.Do(func() interface{} {
+		result := LimitsConfig{
+			Policy: LimitsPolicyDefer,
+			Assets: make(map[string]AssetLimits),
+		}
+
+		raw := kv.MustGetStringMap(c.getter, "limits")
+		err := figure.
+			Out(&result).
+			With(figure.BaseHooks).
+			From(raw).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out limits"))
+		}
+		if err := result.Validate(); err != nil {
+			panic(errors.Wrap(err, "invalid limits config"))
+		}
+
+		err = figureAssets(raw, func(code string, values map[string]interface{}) error {
+			limits := AssetLimits{
+				Window: 24 * time.Hour,
+			}
+			err := figure.
+				Out(&limits).
+				With(figure.BaseHooks, amountHooks).
+				From(values).
+				Please()
+			if err != nil {
+				return err
+			}
+			if limits.Window <= 0 {
+				return errors.New("limits window must be positive")
+			}
+
+			result.Assets[code] = limits
+			return nil
+		})
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out asset limits"))
+		}
+
+		c.limitsConfig = result
+		return nil
+	})
+	return c.limitsConfig
+}
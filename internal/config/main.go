@@ -9,11 +9,24 @@ import (
 var ERC20WithdrawVersion string
 
 type config struct {
-	transferConfig TransferConfig
-	withdrawConfig WithdrawConfig
+	transferConfig  TransferConfig
+	withdrawConfig  WithdrawConfig
+	screeningConfig ScreeningConfig
+	auditConfig     AuditConfig
+	storageConfig   StorageConfig
+	limitsConfig    LimitsConfig
+	reviewConfig    ReviewConfig
+	adminConfig     AdminConfig
+	timelockConfig  TimelockConfig
+	feesConfig      FeesConfig
+	bridgeConfig    BridgeConfig
+	batchConfig     BatchConfig
+	safeConfig      SafeConfig
+	privateConfig   PrivateConfig
 
-	getter kv.Getter
-	once   comfig.Once
+	getter     kv.Getter
+	once       comfig.Once
+	chainsOnce comfig.Once
 	Horizoner
 	Ether
 	comfig.Logger
@@ -22,16 +35,30 @@ type config struct {
 type Config interface {
 	WithdrawConfig() WithdrawConfig
 	TransferConfig() TransferConfig
+	ScreeningConfig() ScreeningConfig
+	AuditConfig() AuditConfig
+	StorageConfig() StorageConfig
+	LimitsConfig() LimitsConfig
+	ReviewConfig() ReviewConfig
+	AdminConfig() AdminConfig
+	TimelockConfig() TimelockConfig
+	FeesConfig() FeesConfig
+	BridgeConfig() BridgeConfig
+	BatchConfig() BatchConfig
+	SafeConfig() SafeConfig
+	PrivateConfig() PrivateConfig
+	Chains() map[string]Chain
 	Log() *logan.Entry
 	Horizoner
 	Ether
 }
 
 func NewConfig(getter kv.Getter) Config {
+	logger := comfig.NewLogger(getter, comfig.LoggerOpts{Release: ERC20WithdrawVersion})
 	return &config{
 		getter:    getter,
 		Horizoner: NewHorizoner(getter),
-		Ether:     NewEther(getter),
-		Logger:    comfig.NewLogger(getter, comfig.LoggerOpts{Release: ERC20WithdrawVersion}),
+		Ether:     NewEther(getter, logger),
+		Logger:    logger,
 	}
 }
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"crypto/ecdsa"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/spf13/cast"
+	"github.com/tokend/erc20-withdraw-svc/internal/broadcast"
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+// DefaultFallbackBlocks is a number of blocks private transaction waits for relay before public broadcast
+const DefaultFallbackBlocks = 25
+
+// RelayConfig is a private relay of the chain transactions can be submitted to, bypassing public mempool
+type RelayConfig struct {
+	Endpoint string `fig:"endpoint,required"`
+	// Method is either `private` or `bundle`
+	Method         string `fig:"method"`
+	FallbackBlocks uint64 `fig:"fallback_blocks"`
+	// AuthSeed is an optional key relay requests are signed with
+	AuthSeed string `fig:"auth_seed"`
+}
+
+func (c RelayConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Method, validation.In(broadcast.MethodPrivate, broadcast.MethodBundle)),
+		validation.Field(&c.FallbackBlocks, validation.Min(uint64(1))),
+	)
+}
+
+// AuthKey returns key relay requests are signed with, nil if it is not configured
+func (c RelayConfig) AuthKey() *ecdsa.PrivateKey {
+	if c.AuthSeed == "" {
+		return nil
+	}
+	key, err := crypto.HexToECDSA(c.AuthSeed)
+	if err != nil {
+		panic(errors.Wrap(err, "invalid relay auth seed"))
+	}
+	return key
+}
+
+func figureRelay(values map[string]interface{}) (*RelayConfig, error) {
+	raw, ok := values["relay"]
+	if !ok {
+		return nil, nil
+	}
+	relayValues, err := cast.ToStringMapE(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse relay")
+	}
+
+	relay := RelayConfig{
+		Method:         broadcast.MethodPrivate,
+		FallbackBlocks: DefaultFallbackBlocks,
+	}
+	err = figure.
+		Out(&relay).
+		With(figure.BaseHooks).
+		From(relayValues).
+		Please()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to figure out relay")
+	}
+	if err := relay.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid relay")
+	}
+
+	return &relay, nil
+}
+
+// AssetPrivate sends withdrawals above threshold through relay of the asset chain, threshold is in TokenD amount
+type AssetPrivate struct {
+	Threshold regources.Amount `fig:"threshold"`
+}
+
+type PrivateConfig struct {
+	// Thresholds by asset code, withdrawals of assets not listed here are broadcast publicly
+	Assets map[string]AssetPrivate
+}
+
+func (c *config) PrivateConfig() PrivateConfig {
+	c.once.Do(func() interface{} {
+		result := PrivateConfig{
+			Assets: make(map[string]AssetPrivate),
+		}
+
+		err := figureAssets(kv.MustGetStringMap(c.getter, "private"), func(code string, values map[string]interface{}) error {
+			var private AssetPrivate
+			err := figure.
+				Out(&private).
+				With(figure.BaseHooks, amountHooks).
+				From(values).
+				Please()
+			if err != nil {
+				return err
+			}
+
+			result.Assets[code] = private
+			return nil
+		})
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out private"))
+		}
+
+		c.privateConfig = result
+		return nil
+	})
+	return c.privateConfig
+}
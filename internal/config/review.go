@@ -0,0 +1,45 @@
+package config
+
+import (
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+type ReviewConfig struct {
+	// Withdrawals above threshold wait for manual approval, by asset code
+	Thresholds map[string]regources.Amount
+}
+
+func (c *config) ReviewConfig() ReviewConfig {
+	c.once.Do(func() interface{} {
+		result := ReviewConfig{
+			Thresholds: make(map[string]regources.Amount),
+		}
+
+		err := figureAssets(kv.MustGetStringMap(c.getter, "review"), func(code string, values map[string]interface{}) error {
+			var asset struct {
+				Threshold regources.Amount `fig:"threshold,required"`
+			}
+			err := figure.
+				Out(&asset).
+				With(figure.BaseHooks, amountHooks).
+				From(values).
+				Please()
+			if err != nil {
+				return err
+			}
+
+			result.Thresholds[code] = asset.Threshold
+			return nil
+		})
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out review"))
+		}
+
+		c.reviewConfig = result
+		return nil
+	})
+	return c.reviewConfig
+}
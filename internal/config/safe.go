@@ -0,0 +1,63 @@
+package config
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+// AssetSafe sends withdrawals above threshold from Safe multisig, threshold is in TokenD amount.
+// Hot wallet must be one of the Safe owners, it signs and executes Safe transactions.
+type AssetSafe struct {
+	Address   string           `fig:"address,required"`
+	Threshold regources.Amount `fig:"threshold"`
+	// GasLimit is a gas limit of Safe execution, transfer gas limit is used if not set
+	GasLimit uint64 `fig:"gas_limit"`
+}
+
+func (c AssetSafe) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Address, validation.NewStringRule(common.IsHexAddress, "must be valid safe address")),
+	)
+}
+
+type SafeConfig struct {
+	// Safes by asset code, withdrawals of assets not listed here are sent from hot wallet
+	Assets map[string]AssetSafe
+}
+
+func (c *config) SafeConfig() SafeConfig {
+	c.once.Do(func() interface{} {
+		result := SafeConfig{
+			Assets: make(map[string]AssetSafe),
+		}
+
+		err := figureAssets(kv.MustGetStringMap(c.getter, "safe"), func(code string, values map[string]interface{}) error {
+			var safe AssetSafe
+			err := figure.
+				Out(&safe).
+				With(figure.BaseHooks, amountHooks).
+				From(values).
+				Please()
+			if err != nil {
+				return err
+			}
+			if err := safe.Validate(); err != nil {
+				return err
+			}
+
+			result.Assets[code] = safe
+			return nil
+		})
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out safe"))
+		}
+
+		c.safeConfig = result
+		return nil
+	})
+	return c.safeConfig
+}
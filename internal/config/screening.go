@@ -0,0 +1,56 @@
+package config
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	//ScreeningPolicyHold holds request for manual review on deny list hit
+	ScreeningPolicyHold = "hold"
+	//ScreeningPolicyReject permanently rejects request on deny list hit
+	ScreeningPolicyReject = "reject"
+)
+
+type ScreeningConfig struct {
+	// Paths to deny lists, either .csv or .json
+	Lists        []string      `fig:"lists"`
+	Policy       string        `fig:"policy"`
+	ReloadPeriod time.Duration `fig:"reload_period"`
+}
+
+func (c ScreeningConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Policy, validation.Required, validation.In(ScreeningPolicyHold, ScreeningPolicyReject)),
+		validation.Field(&c.ReloadPeriod, validation.Required),
+	)
+}
+
+func (c *config) ScreeningConfig() ScreeningConfig {
+	c.once.Do(func() interface{} {
+		result := ScreeningConfig{
+			Policy:       ScreeningPolicyHold,
+			ReloadPeriod: 10 * time.Second,
+		}
+
+		err := figure.
+			Out(&result).
+			With(figure.BaseHooks).
+			From(kv.MustGetStringMap(c.getter, "screening")).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out screening"))
+		}
+		if err := result.Validate(); err != nil {
+			panic(errors.Wrap(err, "invalid screening config"))
+		}
+
+		c.screeningConfig = result
+		return nil
+	})
+	return c.screeningConfig
+}
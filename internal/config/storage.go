@@ -0,0 +1,33 @@
+package config
+
+import (
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type StorageConfig struct {
+	// Directory local service state is persisted in
+	Dir string `fig:"dir"`
+}
+
+func (c *config) StorageConfig() StorageConfig {
+	c.once.Do(func() interface{} {
+		result := StorageConfig{
+			Dir: ".",
+		}
+
+		err := figure.
+			Out(&result).
+			With(figure.BaseHooks).
+			From(kv.MustGetStringMap(c.getter, "storage")).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out storage"))
+		}
+
+		c.storageConfig = result
+		return nil
+	})
+	return c.storageConfig
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"time"
+
+	"gitlab.com/distributed_lab/figure"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+// AssetTimelock delays withdrawals above threshold, threshold is in TokenD amount
+type AssetTimelock struct {
+	Threshold regources.Amount `fig:"threshold"`
+	Delay     time.Duration    `fig:"delay,required"`
+}
+
+type TimelockConfig struct {
+	// Time locks by asset code, withdrawals of assets not listed here are sent immediately
+	Assets map[string]AssetTimelock
+}
+
+func (c *config) TimelockConfig() TimelockConfig {
+	c.once.Do(func() interface{} {
+		result := TimelockConfig{
+			Assets: make(map[string]AssetTimelock),
+		}
+
+		err := figureAssets(kv.MustGetStringMap(c.getter, "timelock"), func(code string, values map[string]interface{}) error {
+			var timelock AssetTimelock
+			err := figure.
+				Out(&timelock).
+				With(figure.BaseHooks, amountHooks).
+				From(values).
+				Please()
+			if err != nil {
+				return err
+			}
+			if timelock.Delay <= 0 {
+				return errors.New("time lock delay must be positive")
+			}
+
+			result.Assets[code] = timelock
+			return nil
+		})
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out timelock"))
+		}
+
+		c.timelockConfig = result
+		return nil
+	})
+	return c.timelockConfig
+}
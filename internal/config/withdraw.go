@@ -1,6 +1,9 @@
 package config
 
 import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
 	"gitlab.com/distributed_lab/figure"
 	"gitlab.com/distributed_lab/kit/kv"
 	"gitlab.com/distributed_lab/logan/v3/errors"
@@ -8,14 +11,44 @@ import (
 	"gitlab.com/tokend/keypair/figurekeypair"
 )
 
+const (
+	// RemainderPolicyRoundDown sends amount rounded down to token precision and reports remainder
+	RemainderPolicyRoundDown = "round_down"
+	// RemainderPolicyReject permanently rejects withdrawal which can't be converted exactly
+	RemainderPolicyReject = "reject"
+	// RemainderPolicyExact refuses to serve assets which trailing digits exceed token decimals
+	RemainderPolicyExact = "exact"
+
+	// DefaultPollingPeriod is how often pending requests are listed from Horizon
+	DefaultPollingPeriod = 15 * time.Second
+)
+
 type WithdrawConfig struct {
 	Signer keypair.Full `fig:"signer"`
 	// Asset owner is used as tx source
+
+	// What to do with part of the amount token can't represent
+	Remainder string `fig:"remainder"`
+	// PollingPeriod is how often verifier lists pending requests, confirmations of already known ones
+	// are checked on every new head
+	PollingPeriod time.Duration `fig:"polling_period"`
+}
+
+func (c WithdrawConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Remainder, validation.Required, validation.In(
+			RemainderPolicyRoundDown, RemainderPolicyReject, RemainderPolicyExact,
+		)),
+		validation.Field(&c.PollingPeriod, validation.Required),
+	)
 }
 
 func (c *config) WithdrawConfig() WithdrawConfig {
 	c.once.Do(func() interface{} {
-		var result WithdrawConfig
+		result := WithdrawConfig{
+			Remainder:     RemainderPolicyRoundDown,
+			PollingPeriod: DefaultPollingPeriod,
+		}
 
 		err := figure.
 			Out(&result).
@@ -25,6 +58,9 @@ func (c *config) WithdrawConfig() WithdrawConfig {
 		if err != nil {
 			panic(errors.Wrap(err, "failed to figure out withdraw"))
 		}
+		if err := result.Validate(); err != nil {
+			panic(errors.Wrap(err, "invalid withdraw config"))
+		}
 
 		c.withdrawConfig = result
 		return nil
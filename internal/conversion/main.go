@@ -0,0 +1,83 @@
+package conversion
+
+import (
+	"math/big"
+
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// Precision is number of fractional digits of TokenD amounts
+const Precision = 6
+
+var ErrOverflow = errors.New("amount does not fit into TokenD amount")
+
+// Converter converts amounts between TokenD and ERC20 token units exactly
+type Converter struct {
+	trailingDigits uint32
+	decimals       uint32
+	scale          *big.Int
+}
+
+// New creates converter for asset with trailing digits and token with decimals,
+// trailing digits above Precision are treated as Precision
+func New(trailingDigits, decimals uint32) Converter {
+	if trailingDigits > Precision {
+		trailingDigits = Precision
+	}
+
+	diff := int64(decimals) - Precision
+	if diff < 0 {
+		diff = -diff
+	}
+
+	return Converter{
+		trailingDigits: trailingDigits,
+		decimals:       decimals,
+		scale:          new(big.Int).Exp(big.NewInt(10), big.NewInt(diff), nil),
+	}
+}
+
+// Exact returns true if any valid TokenD amount of the asset converts without remainder
+func (c Converter) Exact() bool {
+	return c.decimals >= c.trailingDigits
+}
+
+// Decimals returns number of fractional digits of the token
+func (c Converter) Decimals() uint32 {
+	return c.decimals
+}
+
+// ToToken converts TokenD amount to token units, remainder is a part of the amount
+// in TokenD units too small to be represented by the token
+func (c Converter) ToToken(amount uint64) (value *big.Int, remainder uint64) {
+	value = new(big.Int).SetUint64(amount)
+	if c.decimals >= Precision {
+		return value.Mul(value, c.scale), 0
+	}
+
+	rem := new(big.Int)
+	value.QuoRem(value, c.scale, rem)
+	return value, rem.Uint64()
+}
+
+// FromToken converts token units to TokenD amount, dropping digits TokenD can't represent
+func (c Converter) FromToken(value *big.Int) (amount uint64, remainder *big.Int, err error) {
+	if value.Sign() < 0 {
+		return 0, nil, errors.From(errors.New("amount must not be negative"), logan.F{"value": value.String()})
+	}
+
+	result := new(big.Int).Set(value)
+	remainder = new(big.Int)
+	if c.decimals >= Precision {
+		result.QuoRem(result, c.scale, remainder)
+	} else {
+		result.Mul(result, c.scale)
+	}
+
+	if !result.IsUint64() {
+		return 0, nil, errors.From(ErrOverflow, logan.F{"value": value.String()})
+	}
+
+	return result.Uint64(), remainder, nil
+}
@@ -0,0 +1,148 @@
+package conversion
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const maxDecimals = 36
+
+func pow10(n uint32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func forEachPair(t *testing.T, fn func(t *testing.T, trailingDigits, decimals uint32)) {
+	for trailingDigits := uint32(0); trailingDigits <= Precision; trailingDigits++ {
+		for decimals := uint32(0); decimals <= maxDecimals; decimals++ {
+			fn(t, trailingDigits, decimals)
+		}
+	}
+}
+
+func TestToToken(t *testing.T) {
+	forEachPair(t, func(t *testing.T, trailingDigits, decimals uint32) {
+		converter := New(trailingDigits, decimals)
+
+		// value and remainder always add up to the original amount
+		restores := func(amount uint64) bool {
+			value, remainder := converter.ToToken(amount)
+			restored, dropped, err := converter.FromToken(value)
+			if err != nil || dropped.Sign() != 0 {
+				return false
+			}
+			return restored+remainder == amount
+		}
+		assert.NoError(t, quick.Check(restores, nil), "trailing digits %d, decimals %d", trailingDigits, decimals)
+
+		// remainder is less than the smallest token unit
+		bounded := func(amount uint64) bool {
+			_, remainder := converter.ToToken(amount)
+			if decimals >= Precision {
+				return remainder == 0
+			}
+			return new(big.Int).SetUint64(remainder).Cmp(pow10(Precision-decimals)) < 0
+		}
+		assert.NoError(t, quick.Check(bounded, nil), "trailing digits %d, decimals %d", trailingDigits, decimals)
+
+		// amounts valid for the asset convert without remainder if converter is exact
+		exact := func(amount uint64) bool {
+			step := pow10(Precision - trailingDigits).Uint64()
+			_, remainder := converter.ToToken(amount / step * step)
+			return !converter.Exact() || remainder == 0
+		}
+		assert.NoError(t, quick.Check(exact, nil), "trailing digits %d, decimals %d", trailingDigits, decimals)
+
+		// conversion preserves order
+		monotonic := func(a, b uint64) bool {
+			if a > b {
+				a, b = b, a
+			}
+			va, _ := converter.ToToken(a)
+			vb, _ := converter.ToToken(b)
+			return va.Cmp(vb) <= 0
+		}
+		assert.NoError(t, quick.Check(monotonic, nil), "trailing digits %d, decimals %d", trailingDigits, decimals)
+	})
+}
+
+func TestToTokenMaxAmount(t *testing.T) {
+	value, remainder := New(Precision, 18).ToToken(math.MaxUint64)
+	expected, _ := new(big.Int).SetString("18446744073709551615000000000000", 10)
+	assert.Equal(t, expected, value)
+	assert.Equal(t, uint64(0), remainder)
+
+	value, remainder = New(Precision, 2).ToToken(math.MaxUint64)
+	assert.Equal(t, new(big.Int).SetUint64(1844674407370955), value)
+	assert.Equal(t, uint64(1615), remainder)
+}
+
+func TestFromToken(t *testing.T) {
+	t.Run("overflow", func(t *testing.T) {
+		_, _, err := New(Precision, 0).FromToken(new(big.Int).SetUint64(math.MaxUint64))
+		assert.Error(t, err)
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		_, _, err := New(Precision, 18).FromToken(big.NewInt(-1))
+		assert.Error(t, err)
+	})
+
+	t.Run("dust", func(t *testing.T) {
+		amount, remainder, err := New(Precision, 18).FromToken(big.NewInt(1000000000001))
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), amount)
+		assert.Equal(t, big.NewInt(1), remainder)
+	})
+}
+
+// baselineToToken is conversion of previous versions, which scaled amount by trailing digits instead of Precision
+func baselineToToken(trailingDigits, decimals uint32, amount uint64) *big.Int {
+	value := new(big.Int).SetUint64(amount)
+	if trailingDigits > decimals {
+		return value.Quo(value, pow10(trailingDigits-decimals))
+	}
+	return value.Mul(value, pow10(decimals-trailingDigits))
+}
+
+func TestBaselineMigration(t *testing.T) {
+	cases := []struct {
+		trailingDigits, decimals uint32
+		amount                   uint64
+		baseline, current        string
+	}{
+		// 1.00 of asset with 2 trailing digits sent 10000 tokens before
+		{trailingDigits: 2, decimals: 18, amount: 1000000, baseline: "10000000000000000000000", current: "1000000000000000000"},
+		{trailingDigits: 0, decimals: 18, amount: 3000000, baseline: "3000000000000000000000000", current: "3000000000000000000"},
+		{trailingDigits: 4, decimals: 6, amount: 1230000, baseline: "123000000", current: "1230000"},
+		{trailingDigits: 2, decimals: 2, amount: 1500000, baseline: "1500000", current: "150"},
+		// assets with Precision trailing digits are converted the same way
+		{trailingDigits: 6, decimals: 18, amount: 1000000, baseline: "1000000000000000000", current: "1000000000000000000"},
+		{trailingDigits: 6, decimals: 2, amount: 1500000, baseline: "150", current: "150"},
+	}
+	for _, c := range cases {
+		baseline := baselineToToken(c.trailingDigits, c.decimals, c.amount)
+		current, _ := New(c.trailingDigits, c.decimals).ToToken(c.amount)
+		assert.Equal(t, c.baseline, baseline.String(), "trailing digits %d, decimals %d", c.trailingDigits, c.decimals)
+		assert.Equal(t, c.current, current.String(), "trailing digits %d, decimals %d", c.trailingDigits, c.decimals)
+	}
+
+	// amounts valid for asset differ exactly by 10^(Precision - trailing digits)
+	forEachPair(t, func(t *testing.T, trailingDigits, decimals uint32) {
+		if decimals < Precision {
+			return
+		}
+		converter := New(trailingDigits, decimals)
+		step := pow10(Precision - trailingDigits).Uint64()
+		differs := func(units uint32) bool {
+			amount := uint64(units) * step
+			current, _ := converter.ToToken(amount)
+			expected := new(big.Int).Mul(current, pow10(Precision-trailingDigits))
+			return baselineToToken(trailingDigits, decimals, amount).Cmp(expected) == 0
+		}
+		assert.NoError(t, quick.Check(differs, nil), "trailing digits %d, decimals %d", trailingDigits, decimals)
+	})
+}
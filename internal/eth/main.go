@@ -0,0 +1,23 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Client is an access to EVM chain services depend on: contract calls, transacting, nonces, logs,
+// receipts, headers and chain id. It is implemented by node pool and by in-process simulated chain.
+type Client interface {
+	bind.ContractBackend
+
+	ChainID(ctx context.Context) (*big.Int, error)
+	// HeaderByNumber returns header of block, the latest one if number is nil
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
@@ -0,0 +1,192 @@
+package simulated
+
+import (
+	"encoding/binary"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// ERC20ABI is an ABI of test token, it has `mint(address,uint256)` callable by anyone
+const ERC20ABI = `[
+	{"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"payable":false,"stateMutability":"view","type":"function"},
+	{"constant":true,"inputs":[],"name":"totalSupply","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},
+	{"constant":true,"inputs":[{"name":"_owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"balance","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},
+	{"constant":false,"inputs":[{"name":"_to","type":"address"},{"name":"_value","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"payable":false,"stateMutability":"nonpayable","type":"function"},
+	{"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"name":"mint","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"}
+]`
+
+// ERC20Decimals is a number of decimals of test token
+const ERC20Decimals = 18
+
+// ERC20 is a deployed test token
+type ERC20 struct {
+	Address  common.Address
+	contract *bind.BoundContract
+}
+
+// DeployERC20 deploys test token from opts.From, transaction is mined right away
+func DeployERC20(opts *bind.TransactOpts, backend bind.ContractBackend) (*ERC20, error) {
+	parsed, err := abi.JSON(strings.NewReader(ERC20ABI))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse test token ABI")
+	}
+	address, _, contract, err := bind.DeployContract(opts, parsed, erc20Code(), backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to deploy test token")
+	}
+	return &ERC20{Address: address, contract: contract}, nil
+}
+
+// Mint mints amount of tokens to the address
+func (t *ERC20) Mint(opts *bind.TransactOpts, to common.Address, amount *big.Int) (*types.Transaction, error) {
+	return t.contract.Transact(opts, "mint", to, amount)
+}
+
+// Transfer transfers amount of tokens from opts.From to the address
+func (t *ERC20) Transfer(opts *bind.TransactOpts, to common.Address, amount *big.Int) (*types.Transaction, error) {
+	return t.contract.Transact(opts, "transfer", to, amount)
+}
+
+// BalanceOf returns token balance of the address
+func (t *ERC20) BalanceOf(opts *bind.CallOpts, owner common.Address) (*big.Int, error) {
+	balance := new(*big.Int)
+	if err := t.contract.Call(opts, balance, "balanceOf", owner); err != nil {
+		return nil, errors.Wrap(err, "failed to get balance")
+	}
+	return *balance, nil
+}
+
+// erc20Code returns creation code of test token.
+// Total supply is kept in slot 0, balance of address is kept in slot `address | 1 << 160`.
+func erc20Code() []byte {
+	balanceKey := common.LeftPadBytes([]byte{1}, 21)
+	transferTopic := crypto.Keccak256([]byte("Transfer(address,address,uint256)"))
+	selector := func(signature string) []byte {
+		return crypto.Keccak256([]byte(signature))[:4]
+	}
+	word := func(p *program) {
+		p.push(0).op(vm.MSTORE).push(32).push(0).op(vm.RETURN)
+	}
+
+	p := newProgram()
+	// dispatch by the first 4 bytes of calldata
+	p.push(0).op(vm.CALLDATALOAD).push(0xe0).op(vm.SHR)
+	for _, method := range []struct {
+		signature string
+		label     string
+	}{
+		{"decimals()", "decimals"},
+		{"totalSupply()", "totalSupply"},
+		{"balanceOf(address)", "balanceOf"},
+		{"transfer(address,uint256)", "transfer"},
+		{"mint(address,uint256)", "mint"},
+	} {
+		p.op(vm.DUP1).pushBytes(selector(method.signature)).op(vm.EQ).jumpi(method.label)
+	}
+	p.push(0).op(vm.DUP1).op(vm.REVERT)
+
+	p.label("decimals").push(ERC20Decimals)
+	word(p)
+
+	p.label("totalSupply").push(0).op(vm.SLOAD)
+	word(p)
+
+	p.label("balanceOf").push(4).op(vm.CALLDATALOAD).pushBytes(balanceKey).op(vm.OR, vm.SLOAD)
+	word(p)
+
+	// [value] -> [value, fromSlot, balance], reverts if balance is not enough
+	p.label("transfer").push(0x24).op(vm.CALLDATALOAD)
+	p.op(vm.CALLER).pushBytes(balanceKey).op(vm.OR, vm.DUP1, vm.SLOAD)
+	p.op(vm.DUP3, vm.DUP2, vm.LT).jumpi("fail")
+	// balance of sender is decreased, balance of receiver is increased
+	p.op(vm.DUP3, vm.SWAP1, vm.SUB, vm.SWAP1, vm.SSTORE)
+	p.push(4).op(vm.CALLDATALOAD).pushBytes(balanceKey).op(vm.OR, vm.DUP1, vm.SLOAD, vm.DUP3, vm.ADD, vm.SWAP1, vm.SSTORE)
+	// Transfer(caller, to, value)
+	p.push(0).op(vm.MSTORE)
+	p.push(4).op(vm.CALLDATALOAD, vm.CALLER).pushBytes(transferTopic).push(32).push(0).op(vm.LOG3)
+	p.push(1)
+	word(p)
+
+	p.label("mint").push(0x24).op(vm.CALLDATALOAD)
+	p.push(4).op(vm.CALLDATALOAD).pushBytes(balanceKey).op(vm.OR, vm.DUP1, vm.SLOAD, vm.DUP3, vm.ADD, vm.SWAP1, vm.SSTORE)
+	p.op(vm.DUP1).push(0).op(vm.SLOAD, vm.ADD).push(0).op(vm.SSTORE)
+	// Transfer(0, to, value)
+	p.push(0).op(vm.MSTORE)
+	p.push(4).op(vm.CALLDATALOAD).push(0).pushBytes(transferTopic).push(32).push(0).op(vm.LOG3, vm.STOP)
+
+	p.label("fail").push(0).op(vm.DUP1).op(vm.REVERT)
+
+	runtime := p.bytes()
+
+	// creation code copies runtime code to memory and returns it
+	init := newProgram()
+	size := make([]byte, 2)
+	binary.BigEndian.PutUint16(size, uint16(len(runtime)))
+	init.pushBytes(size).op(vm.DUP1).pushBytes([]byte{0, initSize}).push(0).op(vm.CODECOPY).push(0).op(vm.RETURN)
+	return append(init.bytes(), runtime...)
+}
+
+// initSize is a size of creation code prefix in bytes
+const initSize = 13
+
+// program is a minimal EVM assembler resolving jump labels
+type program struct {
+	code   []byte
+	labels map[string]int
+	// jumps are positions of 2 byte jump destinations by label
+	jumps map[int]string
+}
+
+func newProgram() *program {
+	return &program{labels: make(map[string]int), jumps: make(map[int]string)}
+}
+
+func (p *program) op(ops ...vm.OpCode) *program {
+	for _, op := range ops {
+		p.code = append(p.code, byte(op))
+	}
+	return p
+}
+
+func (p *program) push(value uint64) *program {
+	return p.pushBytes(new(big.Int).SetUint64(value).Bytes())
+}
+
+func (p *program) pushBytes(value []byte) *program {
+	if len(value) == 0 {
+		value = []byte{0}
+	}
+	p.code = append(p.code, byte(vm.PUSH1)+byte(len(value)-1))
+	p.code = append(p.code, value...)
+	return p
+}
+
+func (p *program) label(name string) *program {
+	p.labels[name] = len(p.code)
+	return p.op(vm.JUMPDEST)
+}
+
+func (p *program) jumpi(label string) *program {
+	p.jumps[len(p.code)+1] = label
+	return p.pushBytes([]byte{0, 0}).op(vm.JUMPI)
+}
+
+func (p *program) bytes() []byte {
+	for position, label := range p.jumps {
+		destination, ok := p.labels[label]
+		if !ok {
+			panic(errors.New("unknown label " + label))
+		}
+		binary.BigEndian.PutUint16(p.code[position:], uint16(destination))
+	}
+	return p.code
+}
@@ -0,0 +1,409 @@
+package simulated
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/tokend/erc20-withdraw-svc/internal/eth"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// DefaultGasLimit is a block gas limit of simulated chain
+const DefaultGasLimit = 8000000
+
+var _ eth.Client = (*Backend)(nil)
+
+// Backend is an in-process chain built on go-ethereum core, the same one simulated backend of bindings uses.
+// Unlike bindings one, it accepts EIP-155 transactions, so services sign them the same way they do for real nodes.
+// Every transaction is mined in its own block right away, unless its nonce is ahead of sender one: such transaction
+// waits for the gap to be filled. Commit mines empty blocks to add confirmations.
+type Backend struct {
+	mu         sync.Mutex
+	database   ethdb.Database
+	blockchain *core.BlockChain
+	config     *params.ChainConfig
+	signer     types.Signer
+	// queued are transactions with nonce ahead of their sender one
+	queued map[common.Address][]*types.Transaction
+}
+
+// New creates chain with accounts funded by alloc
+func New(alloc core.GenesisAlloc) *Backend {
+	database := rawdb.NewMemoryDatabase()
+	genesis := core.Genesis{Config: params.AllEthashProtocolChanges, GasLimit: DefaultGasLimit, Alloc: alloc}
+	genesis.MustCommit(database)
+	blockchain, err := core.NewBlockChain(database, nil, genesis.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		panic(errors.Wrap(err, "failed to create simulated chain"))
+	}
+
+	return &Backend{
+		database:   database,
+		blockchain: blockchain,
+		config:     genesis.Config,
+		signer:     types.NewEIP155Signer(genesis.Config.ChainID),
+		queued:     make(map[common.Address][]*types.Transaction),
+	}
+}
+
+// Transactor returns options signing EIP-155 transactions of simulated chain by key
+func (b *Backend) Transactor(key *ecdsa.PrivateKey) *bind.TransactOpts {
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(_ types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != from {
+				return nil, errors.New("not authorized to sign this account")
+			}
+			return types.SignTx(tx, b.signer, key)
+		},
+	}
+}
+
+// Close stops the chain
+func (b *Backend) Close() {
+	b.blockchain.Stop()
+}
+
+// Commit mines an empty block
+func (b *Backend) Commit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.mine(nil)
+}
+
+// mine appends block with txs to the chain, must be called under lock
+func (b *Backend) mine(txs []*types.Transaction) {
+	blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), ethash.NewFaker(), b.database, 1, func(_ int, block *core.BlockGen) {
+		for _, tx := range txs {
+			block.AddTxWithChain(b.blockchain, tx)
+		}
+	})
+	if _, err := b.blockchain.InsertChain(blocks); err != nil {
+		panic(errors.Wrap(err, "failed to insert simulated block"))
+	}
+}
+
+// Reorg replaces depth latest blocks with depth+1 empty ones and returns transactions dropped from the chain,
+// so they can be sent again the way node returns them to its pool
+func (b *Backend) Reorg(depth int) []*types.Transaction {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	parent := b.blockchain.CurrentBlock()
+	if uint64(depth) > parent.NumberU64() {
+		panic(errors.From(errors.New("reorg is deeper than chain"), logan.F{"depth": depth}))
+	}
+	var dropped []*types.Transaction
+	for i := 0; i < depth; i++ {
+		dropped = append(append(types.Transactions{}, parent.Transactions()...), dropped...)
+		parent = b.blockchain.GetBlock(parent.ParentHash(), parent.NumberU64()-1)
+	}
+
+	blocks, _ := core.GenerateChain(b.config, parent, ethash.NewFaker(), b.database, depth+1, func(_ int, block *core.BlockGen) {
+		// replacing blocks must differ from replaced ones even if both are empty
+		block.SetExtra([]byte("reorg"))
+	})
+	if _, err := b.blockchain.InsertChain(blocks); err != nil {
+		panic(errors.Wrap(err, "failed to insert simulated fork"))
+	}
+	return dropped
+}
+
+func (b *Backend) ChainID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(b.config.ChainID), nil
+}
+
+func (b *Backend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		return b.blockchain.CurrentHeader(), nil
+	}
+	header := b.blockchain.GetHeaderByNumber(number.Uint64())
+	if header == nil {
+		return nil, ethereum.NotFound
+	}
+	return header, nil
+}
+
+func (b *Backend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	receipt, _, _, _ := rawdb.ReadReceipt(b.database, txHash, b.config)
+	if receipt == nil {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+func (b *Backend) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	events := make(chan core.ChainHeadEvent)
+	sub := b.blockchain.SubscribeChainHeadEvent(events)
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case head := <-events:
+				select {
+				case ch <- head.Block.Header():
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// state returns state at block number, the latest one if number is nil
+func (b *Backend) state(number *big.Int) (*state.StateDB, *types.Header, error) {
+	header, err := b.HeaderByNumber(context.Background(), number)
+	if err != nil {
+		return nil, nil, err
+	}
+	statedb, err := b.blockchain.StateAt(header.Root)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get state", logan.F{"number": header.Number})
+	}
+	return statedb, header, nil
+}
+
+func (b *Backend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	statedb, _, err := b.state(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return statedb.GetCode(contract), nil
+}
+
+func (b *Backend) PendingCodeAt(ctx context.Context, contract common.Address) ([]byte, error) {
+	return b.CodeAt(ctx, contract, nil)
+}
+
+func (b *Backend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	statedb, _, err := b.state(nil)
+	if err != nil {
+		return 0, err
+	}
+	return statedb.GetNonce(account), nil
+}
+
+func (b *Backend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (b *Backend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	statedb, header, err := b.state(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	result, _, failed, err := b.call(call, header, statedb)
+	if err != nil {
+		return nil, err
+	}
+	if failed {
+		return nil, errors.New("execution reverted")
+	}
+	return result, nil
+}
+
+// EstimateGas returns the lowest gas limit call succeeds with
+func (b *Backend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	statedb, header, err := b.state(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	succeeds := func(gas uint64) bool {
+		call.Gas = gas
+		_, _, failed, err := b.call(call, header, statedb.Copy())
+		return err == nil && !failed
+	}
+
+	lo, hi := params.TxGas-1, header.GasLimit
+	if !succeeds(hi) {
+		return 0, errors.New("gas required exceeds allowance or always failing transaction")
+	}
+	for lo+1 < hi {
+		mid := (lo + hi) / 2
+		if succeeds(mid) {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi, nil
+}
+
+// call executes call on top of statedb without committing it
+func (b *Backend) call(call ethereum.CallMsg, header *types.Header, statedb *state.StateDB) ([]byte, uint64, bool, error) {
+	if call.Gas == 0 {
+		call.Gas = header.GasLimit
+	}
+	if call.GasPrice == nil {
+		call.GasPrice = big.NewInt(1)
+	}
+	if call.Value == nil {
+		call.Value = new(big.Int)
+	}
+	// caller is funded, so calls don't fail because of gas payment
+	statedb.SetBalance(call.From, new(big.Int).Mul(new(big.Int).SetUint64(call.Gas), call.GasPrice).Add(call.Value, statedb.GetBalance(call.From)))
+
+	msg := types.NewMessage(call.From, call.To, statedb.GetNonce(call.From), call.Value, call.Gas, call.GasPrice, call.Data, false)
+	evm := vm.NewEVM(core.NewEVMContext(msg, header, b.blockchain, nil), statedb, b.config, vm.Config{})
+	return core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(header.GasLimit)).TransitionDb()
+}
+
+// SendTransaction mines transaction in a new block
+func (b *Backend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sender, err := types.Sender(b.signer, tx)
+	if err != nil {
+		return errors.Wrap(err, "invalid transaction signature")
+	}
+	statedb, err := b.blockchain.State()
+	if err != nil {
+		return errors.Wrap(err, "failed to get state")
+	}
+	nonce := statedb.GetNonce(sender)
+	switch {
+	case tx.Nonce() < nonce:
+		return errors.From(errors.New("nonce too low"), logan.F{
+			"expected": nonce,
+			"actual":   tx.Nonce(),
+		})
+	case tx.Nonce() > nonce:
+		// node keeps transaction until the gap is filled, so does the chain
+		b.queued[sender] = append(b.queued[sender], tx)
+		return nil
+	}
+
+	b.mine([]*types.Transaction{tx})
+	b.mineQueued(sender, nonce+1)
+	return nil
+}
+
+// mineQueued mines queued transactions of sender which follow nonce, must be called under lock
+func (b *Backend) mineQueued(sender common.Address, nonce uint64) {
+	for {
+		queued := b.queued[sender]
+		next := -1
+		for i, tx := range queued {
+			if tx.Nonce() == nonce {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return
+		}
+		tx := queued[next]
+		b.queued[sender] = append(queued[:next], queued[next+1:]...)
+		b.mine([]*types.Transaction{tx})
+		nonce++
+	}
+}
+
+func (b *Backend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	from, to := uint64(0), b.blockchain.CurrentHeader().Number.Uint64()
+	if query.BlockHash != nil {
+		header := b.blockchain.GetHeaderByHash(*query.BlockHash)
+		if header == nil {
+			return nil, ethereum.NotFound
+		}
+		from, to = header.Number.Uint64(), header.Number.Uint64()
+	}
+	if query.FromBlock != nil {
+		from = query.FromBlock.Uint64()
+	}
+	if query.ToBlock != nil && query.ToBlock.Uint64() < to {
+		to = query.ToBlock.Uint64()
+	}
+
+	var result []types.Log
+	for number := from; number <= to; number++ {
+		hash := rawdb.ReadCanonicalHash(b.database, number)
+		for _, receipt := range rawdb.ReadReceipts(b.database, hash, number, b.config) {
+			for _, log := range receipt.Logs {
+				if matches(query, log) {
+					result = append(result, *log)
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+func (b *Backend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	events := make(chan []*types.Log)
+	sub := b.blockchain.SubscribeLogsEvent(events)
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case logs := <-events:
+				for _, log := range logs {
+					if !matches(query, log) {
+						continue
+					}
+					select {
+					case ch <- *log:
+					case <-quit:
+						return nil
+					}
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// matches returns true if log satisfies addresses and topics of query
+func matches(query ethereum.FilterQuery, log *types.Log) bool {
+	if len(query.Addresses) > 0 {
+		found := false
+		for _, address := range query.Addresses {
+			found = found || address == log.Address
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(query.Topics) > len(log.Topics) {
+		return false
+	}
+	for i, alternatives := range query.Topics {
+		if len(alternatives) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range alternatives {
+			found = found || topic == log.Topics[i]
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+package simulated
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/tokend/erc20-withdraw-svc/internal/token"
+)
+
+func TestERC20(t *testing.T) {
+	ctx := context.Background()
+	key, _ := crypto.GenerateKey()
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	backend := New(core.GenesisAlloc{owner: {Balance: big.NewInt(1e18)}})
+	defer backend.Close()
+	opts := backend.Transactor(key)
+
+	erc20, err := DeployERC20(opts, backend)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = erc20.Mint(opts, owner, big.NewInt(100))
+	assert.NoError(t, err)
+
+	contract, err := token.New(token.Params{Standard: token.StandardERC20, Address: erc20.Address}, backend)
+	if !assert.NoError(t, err) {
+		return
+	}
+	decimals, err := contract.Decimals(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(ERC20Decimals), decimals)
+
+	transfer := token.Transfer{To: common.HexToAddress("0x01"), Amount: big.NewInt(40)}
+	tx, err := contract.Send(opts, transfer)
+	if !assert.NoError(t, err) {
+		return
+	}
+	receipt, err := backend.TransactionReceipt(ctx, tx.Hash())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	if assert.Len(t, receipt.Logs, 1) {
+		assert.True(t, contract.Delivered(*receipt.Logs[0], transfer))
+	}
+
+	balance, err := erc20.BalanceOf(&bind.CallOpts{Context: ctx}, owner)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(60), balance.Int64())
+
+	// transfer above balance is mined, but fails
+	opts.GasLimit = 100000
+	tx, err = erc20.Transfer(opts, transfer.To, big.NewInt(61))
+	if !assert.NoError(t, err) {
+		return
+	}
+	receipt, err = backend.TransactionReceipt(ctx, tx.Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, types.ReceiptStatusFailed, receipt.Status)
+}
+
+func TestSubscribeNewHead(t *testing.T) {
+	backend := New(core.GenesisAlloc{})
+	defer backend.Close()
+
+	heads := make(chan *types.Header)
+	sub, err := backend.SubscribeNewHead(context.Background(), heads)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer sub.Unsubscribe()
+
+	backend.Commit()
+	select {
+	case head := <-heads:
+		assert.Equal(t, uint64(1), head.Number.Uint64())
+	case <-time.After(time.Second):
+		t.Fatal("head was not received")
+	}
+}
+
+func TestReorg(t *testing.T) {
+	ctx := context.Background()
+	key, _ := crypto.GenerateKey()
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	backend := New(core.GenesisAlloc{owner: {Balance: big.NewInt(1e18)}})
+	defer backend.Close()
+
+	erc20, err := DeployERC20(backend.Transactor(key), backend)
+	if !assert.NoError(t, err) {
+		return
+	}
+	tx, err := erc20.Mint(backend.Transactor(key), owner, big.NewInt(100))
+	if !assert.NoError(t, err) {
+		return
+	}
+	mined, err := backend.TransactionReceipt(ctx, tx.Hash())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	dropped := backend.Reorg(1)
+	if assert.Len(t, dropped, 1) {
+		assert.Equal(t, tx.Hash(), dropped[0].Hash())
+	}
+	head, err := backend.HeaderByNumber(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, mined.BlockNumber.Uint64()+1, head.Number.Uint64())
+	_, err = backend.TransactionReceipt(ctx, tx.Hash())
+	assert.Equal(t, ethereum.NotFound, err)
+
+	// dropped transaction can be included again
+	assert.NoError(t, backend.SendTransaction(ctx, dropped[0]))
+	receipt, err := backend.TransactionReceipt(ctx, tx.Hash())
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, mined.BlockHash, receipt.BlockHash)
+	}
+}
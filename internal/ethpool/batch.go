@@ -0,0 +1,313 @@
+package ethpool
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// DefaultBatchPeriod is how long lookups are collected before they are sent in one batch
+const DefaultBatchPeriod = 200 * time.Millisecond
+
+// lookup is a single call waiting for the next batch
+type lookup struct {
+	key    string
+	method string
+	args   []interface{}
+	// result returns pointer batch response is unmarshaled into
+	result func() interface{}
+	// value extracts looked up value from result, false if node does not know it
+	value func(result interface{}) (interface{}, bool)
+	// fingerprint identifies value when nodes vote on it
+	fingerprint func(value interface{}) common.Hash
+	done        chan outcome
+}
+
+type outcome struct {
+	value interface{}
+	err   error
+}
+
+// Batcher collects receipt and header lookups of all callers and sends them in one JSON-RPC batch per period.
+// If quorum is set, batch is sent to all connected nodes and every value must match on at least quorum of them.
+type Batcher struct {
+	client *Client
+	period time.Duration
+	quorum int
+
+	mu      sync.Mutex
+	pending []*lookup
+	// headers are results of the last batch by number, they are valid until the next one
+	headers map[string]*types.Header
+}
+
+func NewBatcher(client *Client, period time.Duration, quorum int) *Batcher {
+	return &Batcher{
+		client:  client,
+		period:  period,
+		quorum:  quorum,
+		headers: make(map[string]*types.Header),
+	}
+}
+
+// Run sends collected lookups every period until ctx is cancelled
+func (b *Batcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.flush(ctx)
+		}
+	}
+}
+
+// Receipt returns transaction receipt, ethereum.NotFound if transaction is not mined yet
+func (b *Batcher) Receipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	value, err := b.wait(ctx, &lookup{
+		key:    "receipt:" + txHash.Hex(),
+		method: "eth_getTransactionReceipt",
+		args:   []interface{}{txHash},
+		result: func() interface{} {
+			return new(*types.Receipt)
+		},
+		value: func(result interface{}) (interface{}, bool) {
+			receipt := *result.(**types.Receipt)
+			return receipt, receipt != nil
+		},
+		fingerprint: func(value interface{}) common.Hash {
+			return receiptFingerprint(value.(*types.Receipt))
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*types.Receipt), nil
+}
+
+// Header returns header by number, ethereum.NotFound if there is no such block yet
+func (b *Batcher) Header(ctx context.Context, number *big.Int) (*types.Header, error) {
+	key := hexutil.EncodeBig(number)
+	b.mu.Lock()
+	header, ok := b.headers[key]
+	b.mu.Unlock()
+	if ok {
+		return header, nil
+	}
+
+	value, err := b.wait(ctx, &lookup{
+		key:    "header:" + key,
+		method: "eth_getBlockByNumber",
+		args:   []interface{}{key, false},
+		result: func() interface{} {
+			return new(*types.Header)
+		},
+		value: func(result interface{}) (interface{}, bool) {
+			header := *result.(**types.Header)
+			return header, header != nil
+		},
+		fingerprint: func(value interface{}) common.Hash {
+			return value.(*types.Header).Hash()
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*types.Header), nil
+}
+
+func (b *Batcher) wait(ctx context.Context, l *lookup) (interface{}, error) {
+	l.done = make(chan outcome, 1)
+	b.mu.Lock()
+	b.pending = append(b.pending, l)
+	b.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-l.done:
+		return result.value, result.err
+	}
+}
+
+// flush sends pending lookups in one batch, same lookups requested by several callers are sent once
+func (b *Batcher) flush(ctx context.Context) {
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = nil
+	b.headers = make(map[string]*types.Header)
+	b.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	waiters := make(map[string][]*lookup)
+	var unique []*lookup
+	for _, l := range pending {
+		if _, ok := waiters[l.key]; !ok {
+			unique = append(unique, l)
+		}
+		waiters[l.key] = append(waiters[l.key], l)
+	}
+
+	var outcomes []outcome
+	if b.quorum == 0 {
+		outcomes = b.client.batch(ctx, unique)
+	} else {
+		outcomes = b.client.quorumBatch(ctx, unique, b.quorum)
+	}
+
+	b.mu.Lock()
+	for i, l := range unique {
+		if header, ok := outcomes[i].value.(*types.Header); ok && outcomes[i].err == nil {
+			b.headers[l.args[0].(string)] = header
+		}
+	}
+	b.mu.Unlock()
+
+	for i, l := range unique {
+		for _, waiter := range waiters[l.key] {
+			waiter.done <- outcomes[i]
+		}
+	}
+}
+
+// batch sends lookups to the best node, falling back to the next ones if node fails
+func (c *Client) batch(ctx context.Context, lookups []*lookup) []outcome {
+	var err error
+	for _, n := range c.ranked() {
+		raw := n.raw()
+		if raw == nil {
+			continue
+		}
+		var outcomes []outcome
+		outcomes, err = batchCall(ctx, raw, lookups)
+		if isNodeFailure(ctx, err) {
+			n.record(err)
+			c.log.WithError(err).WithField("endpoint", n.endpoint).Debug("ethereum node batch failed, trying next one")
+			continue
+		}
+		if err == nil {
+			n.record(nil)
+			return outcomes
+		}
+		break
+	}
+
+	if err == nil {
+		err = ErrNoNodes
+	}
+	return failed(lookups, errors.Wrap(err, "failed to send batch"))
+}
+
+// quorumBatch sends lookups to all connected nodes, value is returned if at least quorum of nodes agree on it.
+// ethereum.NotFound is returned if there is no quorum and some nodes don't know value yet.
+func (c *Client) quorumBatch(ctx context.Context, lookups []*lookup, quorum int) []outcome {
+	nodes := c.ranked()
+	responses := make([][]outcome, len(nodes))
+	var wg sync.WaitGroup
+	for i, n := range nodes {
+		raw := n.raw()
+		if raw == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, n *node, raw *rpc.Client) {
+			defer wg.Done()
+			outcomes, err := batchCall(ctx, raw, lookups)
+			if err != nil {
+				if isNodeFailure(ctx, err) {
+					n.record(err)
+				}
+				return
+			}
+			n.record(nil)
+			responses[i] = outcomes
+		}(i, n, raw)
+	}
+	wg.Wait()
+
+	result := make([]outcome, len(lookups))
+	for i, l := range lookups {
+		votes := make(map[common.Hash]int)
+		values := make(map[common.Hash]interface{})
+		notFound := false
+		for _, outcomes := range responses {
+			if outcomes == nil {
+				continue
+			}
+			if outcomes[i].err == ethereum.NotFound {
+				notFound = true
+			}
+			if outcomes[i].err != nil {
+				continue
+			}
+			fingerprint := l.fingerprint(outcomes[i].value)
+			votes[fingerprint]++
+			values[fingerprint] = outcomes[i].value
+		}
+
+		result[i].err = errors.From(ErrNoQuorum, logan.F{"key": l.key, "results": len(votes), "quorum": quorum})
+		if notFound {
+			result[i].err = ethereum.NotFound
+		}
+		for fingerprint, count := range votes {
+			if count >= quorum {
+				result[i] = outcome{value: values[fingerprint]}
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// batchCall sends lookups to node in one batch, error is returned only if batch itself failed
+func batchCall(ctx context.Context, raw *rpc.Client, lookups []*lookup) ([]outcome, error) {
+	elems := make([]rpc.BatchElem, len(lookups))
+	for i, l := range lookups {
+		elems[i] = rpc.BatchElem{
+			Method: l.method,
+			Args:   l.args,
+			Result: l.result(),
+		}
+	}
+	if err := raw.BatchCallContext(ctx, elems); err != nil {
+		return nil, err
+	}
+
+	outcomes := make([]outcome, len(lookups))
+	for i, elem := range elems {
+		if elem.Error != nil {
+			outcomes[i].err = elem.Error
+			continue
+		}
+		value, ok := lookups[i].value(elem.Result)
+		if !ok {
+			outcomes[i].err = ethereum.NotFound
+			continue
+		}
+		outcomes[i].value = value
+	}
+
+	return outcomes, nil
+}
+
+func failed(lookups []*lookup, err error) []outcome {
+	outcomes := make([]outcome, len(lookups))
+	for i := range outcomes {
+		outcomes[i].err = err
+	}
+	return outcomes
+}
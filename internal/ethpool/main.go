@@ -0,0 +1,314 @@
+package ethpool
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	// DefaultMaxLag is a number of blocks node may be behind the best one and still serve reads
+	DefaultMaxLag = 3
+
+	healthCheckPeriod = 5 * time.Second
+	// maxErrorRate is an error rate above which node is used only if there are no healthier ones
+	maxErrorRate = 0.5
+	// errorRateDecay is a weight of previous error rate, so recent calls matter the most
+	errorRateDecay = 0.8
+	// reconnectAfter is a number of consecutive failures after which node is dialed again
+	reconnectAfter = 3
+)
+
+// ErrNoNodes is returned if none of the nodes is connected
+var ErrNoNodes = errors.New("no connected ethereum nodes")
+
+// node is a single endpoint with its health
+type node struct {
+	endpoint string
+
+	mu        sync.RWMutex
+	rpc       *rpc.Client
+	client    *ethclient.Client
+	head      uint64
+	errorRate float64
+	failures  int
+	// mismatch is set while node is kept out of the pool for being on another network
+	mismatch error
+}
+
+// Client is an ethereum client backed by several nodes. Reads are routed to healthy nodes which are not behind,
+// transactions are broadcast to all of them, broken nodes are dialed again in background.
+type Client struct {
+	log    *logan.Entry
+	nodes  []*node
+	maxLag uint64
+	pin    Pin
+	cancel context.CancelFunc
+}
+
+// Dial connects to endpoints and keeps checking their health until client is closed.
+// Unreachable endpoints don't fail the dial, they are dialed again on every health check.
+// Node is checked against pin every time it is connected, node on another network is not used.
+func Dial(log *logan.Entry, endpoints []string, maxLag uint64, pin Pin) (*Client, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("at least one endpoint is required")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Client{
+		log:    log.WithField("service", "ethpool"),
+		maxLag: maxLag,
+		pin:    pin,
+		cancel: cancel,
+	}
+	for _, endpoint := range endpoints {
+		c.nodes = append(c.nodes, &node{endpoint: endpoint})
+	}
+
+	c.check(ctx)
+	go c.run(ctx)
+
+	return c, nil
+}
+
+// Close stops health checks and disconnects from all nodes
+func (c *Client) Close() {
+	c.cancel()
+	for _, n := range c.nodes {
+		n.mu.Lock()
+		if n.client != nil {
+			n.client.Close()
+			n.client = nil
+			n.rpc = nil
+		}
+		n.mu.Unlock()
+	}
+}
+
+func (c *Client) run(ctx context.Context) {
+	ticker := time.NewTicker(healthCheckPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.check(ctx)
+		}
+	}
+}
+
+// check dials disconnected nodes and refreshes head of connected ones
+func (c *Client) check(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, n := range c.nodes {
+		wg.Add(1)
+		go func(n *node) {
+			defer wg.Done()
+			c.checkNode(ctx, n)
+		}(n)
+	}
+	wg.Wait()
+}
+
+func (c *Client) checkNode(ctx context.Context, n *node) {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckPeriod)
+	defer cancel()
+
+	client := n.connected()
+	if client == nil {
+		raw, err := rpc.DialContext(ctx, n.endpoint)
+		if err != nil {
+			c.log.WithError(err).WithField("endpoint", n.endpoint).Warn("failed to dial ethereum node")
+			return
+		}
+		if c.pin.isSet() {
+			// node may be switched to another network behind the same endpoint, so it is checked on every connect
+			if _, err := c.pin.Check(ctx, ethclient.NewClient(raw)); err != nil {
+				raw.Close()
+				c.reject(n, err)
+				return
+			}
+		}
+		client = n.connect(raw)
+		c.log.WithField("endpoint", n.endpoint).Info("connected to ethereum node")
+	}
+
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		c.log.WithError(err).WithField("endpoint", n.endpoint).Warn("ethereum node health check failed")
+		n.record(err)
+		return
+	}
+	n.setHead(header.Number.Uint64())
+	n.record(nil)
+}
+
+// reject keeps node out of the pool, it is dialed and checked again on the next health check
+func (c *Client) reject(n *node, err error) {
+	log := c.log.WithError(err).WithField("endpoint", n.endpoint)
+	if !IsMismatch(err) {
+		log.Warn("failed to check network of ethereum node")
+		return
+	}
+	log.Error("ethereum node is on unexpected network, not using it")
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.mismatch = err
+}
+
+// noNodes returns error explaining why there are no connected nodes, mismatch is returned if all nodes
+// are on another network, as waiting for them to come back is pointless
+func (c *Client) noNodes() error {
+	var mismatch error
+	for _, n := range c.nodes {
+		n.mu.RLock()
+		err := n.mismatch
+		n.mu.RUnlock()
+		if err == nil {
+			return ErrNoNodes
+		}
+		mismatch = err
+	}
+	return errors.Wrap(mismatch, "no ethereum nodes on expected network")
+}
+
+// ranked returns connected nodes, healthy and up to date ones first, ordered by error rate
+func (c *Client) ranked() []*node {
+	type candidate struct {
+		node      *node
+		head      uint64
+		errorRate float64
+	}
+	var candidates []candidate
+	var best uint64
+	for _, n := range c.nodes {
+		n.mu.RLock()
+		if n.client != nil {
+			candidates = append(candidates, candidate{node: n, head: n.head, errorRate: n.errorRate})
+			if n.head > best {
+				best = n.head
+			}
+		}
+		n.mu.RUnlock()
+	}
+
+	healthy := func(candidate candidate) bool {
+		return candidate.errorRate < maxErrorRate && best-candidate.head <= c.maxLag
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		hi, hj := healthy(candidates[i]), healthy(candidates[j])
+		if hi != hj {
+			return hi
+		}
+		if candidates[i].errorRate != candidates[j].errorRate {
+			return candidates[i].errorRate < candidates[j].errorRate
+		}
+		return candidates[i].head > candidates[j].head
+	})
+
+	result := make([]*node, 0, len(candidates))
+	for _, candidate := range candidates {
+		result = append(result, candidate.node)
+	}
+	return result
+}
+
+// read calls fn on the best node, falling back to the next ones if node fails
+func (c *Client) read(ctx context.Context, fn func(client *ethclient.Client) error) error {
+	nodes := c.ranked()
+	if len(nodes) == 0 {
+		return c.noNodes()
+	}
+
+	var err error
+	for _, n := range nodes {
+		client := n.connected()
+		if client == nil {
+			continue
+		}
+		err = fn(client)
+		if ctx.Err() != nil {
+			// call aborted by caller tells nothing about node health
+			return err
+		}
+		if err == nil || err == ethereum.NotFound {
+			n.record(nil)
+			return err
+		}
+		// lagging node may not know something others do, so any error is worth asking next node,
+		// but only transport failures make node less healthy
+		if isNodeFailure(ctx, err) {
+			n.record(err)
+		}
+		c.log.WithError(err).WithField("endpoint", n.endpoint).Debug("ethereum node call failed, trying next one")
+	}
+
+	return err
+}
+
+// isNodeFailure returns true if node failed to serve the call, rather than responded with an error
+func isNodeFailure(ctx context.Context, err error) bool {
+	if err == nil || err == ethereum.NotFound || ctx.Err() != nil {
+		return false
+	}
+	_, responded := err.(rpc.Error)
+	return !responded
+}
+
+func (n *node) connected() *ethclient.Client {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.client
+}
+
+// raw returns underlying rpc client for calls ethclient does not provide, e.g. batches
+func (n *node) raw() *rpc.Client {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.rpc
+}
+
+func (n *node) connect(raw *rpc.Client) *ethclient.Client {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.rpc = raw
+	n.client = ethclient.NewClient(raw)
+	n.failures = 0
+	n.mismatch = nil
+	return n.client
+}
+
+func (n *node) setHead(head uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.head = head
+}
+
+// record accounts call result in node error rate, node failing too many times in a row is disconnected
+func (n *node) record(err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.errorRate *= errorRateDecay
+	if err == nil {
+		n.failures = 0
+		return
+	}
+
+	n.errorRate += 1 - errorRateDecay
+	n.failures++
+	if n.failures >= reconnectAfter && n.client != nil {
+		n.client.Close()
+		n.client = nil
+		n.rpc = nil
+	}
+}
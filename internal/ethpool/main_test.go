@@ -0,0 +1,328 @@
+package ethpool
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+func TestRanked(t *testing.T) {
+	connected := ethclient.NewClient(nil)
+	lagging := &node{endpoint: "lagging", client: connected, head: 90}
+	failing := &node{endpoint: "failing", client: connected, head: 100, errorRate: 0.9}
+	healthy := &node{endpoint: "healthy", client: connected, head: 99}
+	disconnected := &node{endpoint: "disconnected", head: 100}
+
+	c := &Client{
+		nodes:  []*node{lagging, failing, disconnected, healthy},
+		maxLag: DefaultMaxLag,
+	}
+
+	assert.Equal(t, []*node{healthy, lagging, failing}, c.ranked())
+}
+
+func TestIsNodeFailure(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, isNodeFailure(ctx, nil))
+	assert.False(t, isNodeFailure(ctx, ethereum.NotFound))
+	assert.True(t, isNodeFailure(ctx, errors.New("connection refused")))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.False(t, isNodeFailure(cancelled, errors.New("context canceled")))
+}
+
+func TestReceiptFingerprint(t *testing.T) {
+	receipt := func() *types.Receipt {
+		return &types.Receipt{
+			Status:    types.ReceiptStatusSuccessful,
+			BlockHash: common.HexToHash("0x01"),
+			Logs: []*types.Log{{
+				Address: common.HexToAddress("0x02"),
+				Topics:  []common.Hash{common.HexToHash("0x03")},
+				Data:    []byte{4},
+			}},
+		}
+	}
+	expected := receiptFingerprint(receipt())
+	assert.Equal(t, expected, receiptFingerprint(receipt()))
+
+	failed := receipt()
+	failed.Status = types.ReceiptStatusFailed
+	assert.NotEqual(t, expected, receiptFingerprint(failed))
+
+	reorged := receipt()
+	reorged.BlockHash = common.HexToHash("0x05")
+	assert.NotEqual(t, expected, receiptFingerprint(reorged))
+
+	forged := receipt()
+	forged.Logs[0].Data = []byte{5}
+	assert.NotEqual(t, expected, receiptFingerprint(forged))
+
+	// variable width encoding made removed log at index 0 look like log at index 1
+	removed := receipt()
+	removed.Logs[0].Removed = true
+	shifted := receipt()
+	shifted.Logs[0].Index = 1
+	assert.NotEqual(t, receiptFingerprint(removed), receiptFingerprint(shifted))
+	assert.NotEqual(t, expected, receiptFingerprint(removed))
+}
+
+// batchNode serves eth_getBlockByNumber with headers of given extra data and counts http requests
+func batchNode(t *testing.T, extra []byte, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		body, err := ioutil.ReadAll(r.Body)
+		if !assert.NoError(t, err) {
+			return
+		}
+		var calls []struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if !assert.NoError(t, json.Unmarshal(body, &calls)) {
+			return
+		}
+
+		var responses []map[string]interface{}
+		for _, call := range calls {
+			var result interface{}
+			if call.Method == "eth_getBlockByNumber" {
+				result = &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(1), Extra: extra}
+			}
+			responses = append(responses, map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      call.ID,
+				"result":  result,
+			})
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(responses))
+	}))
+}
+
+func dialNode(t *testing.T, endpoint string) *node {
+	raw, err := rpc.DialHTTP(endpoint)
+	assert.NoError(t, err)
+	n := &node{endpoint: endpoint}
+	n.connect(raw)
+	return n
+}
+
+func TestBatcher(t *testing.T) {
+	requests := 0
+	server := batchNode(t, nil, &requests)
+	defer server.Close()
+
+	c := &Client{nodes: []*node{dialNode(t, server.URL)}, maxLag: DefaultMaxLag}
+	b := NewBatcher(c, DefaultBatchPeriod, 0)
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	lookup := func(fn func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn()
+		}()
+	}
+	lookup(func() {
+		_, err := b.Receipt(ctx, common.HexToHash("0x01"))
+		assert.Equal(t, ethereum.NotFound, err)
+	})
+	for i := 0; i < 2; i++ {
+		lookup(func() {
+			header, err := b.Header(ctx, big.NewInt(1))
+			if assert.NoError(t, err) {
+				assert.Equal(t, uint64(1), header.Number.Uint64())
+			}
+		})
+	}
+	for queued := 0; queued < 3; {
+		b.mu.Lock()
+		queued = len(b.pending)
+		b.mu.Unlock()
+	}
+	b.flush(ctx)
+	wg.Wait()
+	assert.Equal(t, 1, requests)
+
+	// header is cached until the next batch
+	_, err := b.Header(ctx, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requests)
+}
+
+func TestBatcherQuorum(t *testing.T) {
+	requests := 0
+	honest := batchNode(t, nil, &requests)
+	defer honest.Close()
+	forged := batchNode(t, []byte{1}, &requests)
+	defer forged.Close()
+
+	ctx := context.Background()
+	c := &Client{nodes: []*node{dialNode(t, honest.URL), dialNode(t, forged.URL)}, maxLag: DefaultMaxLag}
+	lookups := []*lookup{{
+		key:         "header",
+		method:      "eth_getBlockByNumber",
+		args:        []interface{}{"0x1", false},
+		result:      func() interface{} { return new(*types.Header) },
+		value:       func(result interface{}) (interface{}, bool) { return *result.(**types.Header), true },
+		fingerprint: func(value interface{}) common.Hash { return value.(*types.Header).Hash() },
+	}}
+
+	outcomes := c.quorumBatch(ctx, lookups, 2)
+	assert.Error(t, outcomes[0].err)
+
+	outcomes = c.quorumBatch(ctx, lookups, 1)
+	assert.NoError(t, outcomes[0].err)
+}
+
+// chainAPI is eth namespace of node on network with given chain id
+type chainAPI struct {
+	mu      sync.Mutex
+	chainID int64
+	nonce   uint64
+}
+
+func (api *chainAPI) GetTransactionCount(account common.Address, block string) hexutil.Uint64 {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return hexutil.Uint64(api.nonce)
+}
+
+func (api *chainAPI) ChainId() *hexutil.Big {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return (*hexutil.Big)(big.NewInt(api.chainID))
+}
+
+func (api *chainAPI) GetBlockByNumber(number rpc.BlockNumber, full bool) *types.Header {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	height := int64(number)
+	if number == rpc.LatestBlockNumber {
+		height = 1
+	}
+	return &types.Header{Number: big.NewInt(height), Difficulty: big.NewInt(api.chainID)}
+}
+
+func (api *chainAPI) switchTo(chainID int64) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.chainID = chainID
+}
+
+func chainNode(t *testing.T, api *chainAPI) *httptest.Server {
+	server := rpc.NewServer()
+	assert.NoError(t, server.RegisterName("eth", api))
+	return httptest.NewServer(server)
+}
+
+func TestPin(t *testing.T) {
+	ctx := context.Background()
+	mainnet := &chainAPI{chainID: 1}
+	mainnetNode := chainNode(t, mainnet)
+	defer mainnetNode.Close()
+	testnet := &chainAPI{chainID: 2}
+	testnetNode := chainNode(t, testnet)
+	defer testnetNode.Close()
+
+	t.Run("mismatching node is kept out", func(t *testing.T) {
+		c, err := Dial(logan.New(), []string{mainnetNode.URL, testnetNode.URL}, DefaultMaxLag, Pin{ChainID: big.NewInt(1)})
+		assert.NoError(t, err)
+		defer c.Close()
+
+		if assert.Len(t, c.ranked(), 1) {
+			assert.Equal(t, mainnetNode.URL, c.ranked()[0].endpoint)
+		}
+		chainID, err := c.ChainID(ctx)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, chainID.Int64())
+	})
+
+	t.Run("genesis", func(t *testing.T) {
+		genesis, err := ethclient.NewClient(dialNode(t, mainnetNode.URL).raw()).HeaderByNumber(ctx, big.NewInt(0))
+		assert.NoError(t, err)
+		pin := Pin{Genesis: genesis.Hash()}
+
+		_, err = pin.Check(ctx, dialNode(t, mainnetNode.URL).connected())
+		assert.NoError(t, err)
+		_, err = pin.Check(ctx, dialNode(t, testnetNode.URL).connected())
+		assert.Equal(t, ErrGenesisMismatch, errors.Cause(err))
+	})
+
+	t.Run("all nodes mismatch", func(t *testing.T) {
+		c, err := Dial(logan.New(), []string{testnetNode.URL}, DefaultMaxLag, Pin{ChainID: big.NewInt(1)})
+		assert.NoError(t, err)
+		defer c.Close()
+
+		_, err = c.ChainID(ctx)
+		assert.Equal(t, ErrChainIDMismatch, errors.Cause(err))
+	})
+
+	t.Run("switched on reconnect", func(t *testing.T) {
+		switching := &chainAPI{chainID: 1}
+		switchingNode := chainNode(t, switching)
+		defer switchingNode.Close()
+		c, err := Dial(logan.New(), []string{switchingNode.URL}, DefaultMaxLag, Pin{ChainID: big.NewInt(1)})
+		assert.NoError(t, err)
+		defer c.Close()
+		assert.Len(t, c.ranked(), 1)
+
+		switching.switchTo(2)
+		for i := 0; i < reconnectAfter; i++ {
+			c.nodes[0].record(errors.New("connection reset"))
+		}
+		c.check(ctx)
+		assert.Empty(t, c.ranked())
+
+		switching.switchTo(1)
+		c.check(ctx)
+		assert.Len(t, c.ranked(), 1)
+	})
+}
+
+func TestRead_Canceled(t *testing.T) {
+	n := &node{endpoint: "canceled", client: ethclient.NewClient(nil), errorRate: 0.5, failures: 1}
+	c := &Client{nodes: []*node{n}, maxLag: DefaultMaxLag}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := c.read(ctx, func(client *ethclient.Client) error {
+		return ctx.Err()
+	})
+	assert.Equal(t, context.Canceled, err)
+	// canceled call is neither success nor failure of node
+	assert.Equal(t, 0.5, n.errorRate)
+	assert.Equal(t, 1, n.failures)
+}
+
+func TestPendingNonceAt(t *testing.T) {
+	behind := chainNode(t, &chainAPI{chainID: 1, nonce: 3})
+	defer behind.Close()
+	ahead := chainNode(t, &chainAPI{chainID: 1, nonce: 5})
+	defer ahead.Close()
+
+	c, err := Dial(logan.New(), []string{behind.URL, ahead.URL, "http://127.0.0.1:1"}, DefaultMaxLag, Pin{})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	nonce, err := c.PendingNonceAt(context.Background(), common.HexToAddress("0x01"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, nonce)
+}
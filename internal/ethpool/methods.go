@@ -0,0 +1,221 @@
+package ethpool
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+func (c *Client) ChainID(ctx context.Context) (result *big.Int, err error) {
+	err = c.read(ctx, func(client *ethclient.Client) (err error) {
+		result, err = client.ChainID(ctx)
+		return err
+	})
+	return result, err
+}
+
+func (c *Client) HeaderByNumber(ctx context.Context, number *big.Int) (result *types.Header, err error) {
+	err = c.read(ctx, func(client *ethclient.Client) (err error) {
+		result, err = client.HeaderByNumber(ctx, number)
+		return err
+	})
+	return result, err
+}
+
+func (c *Client) BlockByNumber(ctx context.Context, number *big.Int) (result *types.Block, err error) {
+	err = c.read(ctx, func(client *ethclient.Client) (err error) {
+		result, err = client.BlockByNumber(ctx, number)
+		return err
+	})
+	return result, err
+}
+
+func (c *Client) TransactionReceipt(ctx context.Context, txHash common.Hash) (result *types.Receipt, err error) {
+	err = c.read(ctx, func(client *ethclient.Client) (err error) {
+		result, err = client.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return result, err
+}
+
+func (c *Client) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) (result []byte, err error) {
+	err = c.read(ctx, func(client *ethclient.Client) (err error) {
+		result, err = client.CodeAt(ctx, account, blockNumber)
+		return err
+	})
+	return result, err
+}
+
+func (c *Client) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) (result []byte, err error) {
+	err = c.read(ctx, func(client *ethclient.Client) (err error) {
+		result, err = client.CallContract(ctx, call, blockNumber)
+		return err
+	})
+	return result, err
+}
+
+func (c *Client) PendingCodeAt(ctx context.Context, account common.Address) (result []byte, err error) {
+	err = c.read(ctx, func(client *ethclient.Client) (err error) {
+		result, err = client.PendingCodeAt(ctx, account)
+		return err
+	})
+	return result, err
+}
+
+// PendingNonceAt asks all connected nodes, as transactions are broadcast to all of them, and returns the highest nonce,
+// so node which has not received some of our pending transactions yet can't make us reuse their nonce
+func (c *Client) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	nodes := c.ranked()
+	if len(nodes) == 0 {
+		return 0, c.noNodes()
+	}
+
+	type result struct {
+		node  *node
+		nonce uint64
+		err   error
+	}
+	results := make(chan result, len(nodes))
+	for _, n := range nodes {
+		go func(n *node) {
+			client := n.connected()
+			if client == nil {
+				results <- result{node: n, err: ErrNoNodes}
+				return
+			}
+			nonce, err := client.PendingNonceAt(ctx, account)
+			results <- result{node: n, nonce: nonce, err: err}
+		}(n)
+	}
+
+	var nonce uint64
+	var lastErr error
+	answered := false
+	for range nodes {
+		r := <-results
+		if r.err != nil {
+			if isNodeFailure(ctx, r.err) {
+				r.node.record(r.err)
+			}
+			lastErr = r.err
+			continue
+		}
+		r.node.record(nil)
+		answered = true
+		if r.nonce > nonce {
+			nonce = r.nonce
+		}
+	}
+
+	if !answered {
+		return 0, errors.Wrap(lastErr, "none of the nodes returned pending nonce")
+	}
+	return nonce, nil
+}
+
+func (c *Client) SuggestGasPrice(ctx context.Context) (result *big.Int, err error) {
+	err = c.read(ctx, func(client *ethclient.Client) (err error) {
+		result, err = client.SuggestGasPrice(ctx)
+		return err
+	})
+	return result, err
+}
+
+func (c *Client) EstimateGas(ctx context.Context, call ethereum.CallMsg) (result uint64, err error) {
+	err = c.read(ctx, func(client *ethclient.Client) (err error) {
+		result, err = client.EstimateGas(ctx, call)
+		return err
+	})
+	return result, err
+}
+
+func (c *Client) FilterLogs(ctx context.Context, query ethereum.FilterQuery) (result []types.Log, err error) {
+	err = c.read(ctx, func(client *ethclient.Client) (err error) {
+		result, err = client.FilterLogs(ctx, query)
+		return err
+	})
+	return result, err
+}
+
+func (c *Client) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (result ethereum.Subscription, err error) {
+	err = c.read(ctx, func(client *ethclient.Client) (err error) {
+		result, err = client.SubscribeFilterLogs(ctx, query, ch)
+		return err
+	})
+	return result, err
+}
+
+// SubscribeNewHead subscribes to heads of the best node, subscription fails if node goes down
+func (c *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (result ethereum.Subscription, err error) {
+	err = c.read(ctx, func(client *ethclient.Client) (err error) {
+		result, err = client.SubscribeNewHead(ctx, ch)
+		return err
+	})
+	return result, err
+}
+
+// SendTransaction broadcasts signed transaction to all connected nodes,
+// it succeeds if at least one of them accepted it.
+func (c *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	nodes := c.ranked()
+	if len(nodes) == 0 {
+		return c.noNodes()
+	}
+
+	type result struct {
+		node *node
+		err  error
+	}
+	results := make(chan result, len(nodes))
+	for _, n := range nodes {
+		go func(n *node) {
+			client := n.connected()
+			if client == nil {
+				results <- result{node: n, err: ErrNoNodes}
+				return
+			}
+			results <- result{node: n, err: client.SendTransaction(ctx, tx)}
+		}(n)
+	}
+
+	var lastErr error
+	accepted := false
+	for range nodes {
+		r := <-results
+		if r.err == nil {
+			r.node.record(nil)
+			accepted = true
+			continue
+		}
+		// node which already knows transaction from another one is still healthy
+		if isKnownTransaction(r.err) {
+			accepted = true
+			continue
+		}
+		if isNodeFailure(ctx, r.err) {
+			r.node.record(r.err)
+		}
+		c.log.WithError(r.err).WithFields(logan.F{
+			"endpoint": r.node.endpoint,
+			"tx_hash":  tx.Hash().Hex(),
+		}).Debug("node rejected transaction")
+		lastErr = r.err
+	}
+
+	if accepted {
+		return nil
+	}
+	return errors.Wrap(lastErr, "none of the nodes accepted transaction")
+}
+
+func isKnownTransaction(err error) bool {
+	msg := err.Error()
+	return msg == "already known" || strings.HasPrefix(msg, "known transaction")
+}
@@ -0,0 +1,70 @@
+package ethpool
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+var (
+	ErrChainIDMismatch = errors.New("node chain id does not match expected one")
+	ErrGenesisMismatch = errors.New("node genesis hash does not match expected one")
+)
+
+// ChainReader is a part of node API network is identified by
+type ChainReader interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Pin is a network nodes must be on, values which are not set are not checked
+type Pin struct {
+	ChainID *big.Int
+	Genesis common.Hash
+}
+
+// Check ensures node is on pinned network, returns chain id reported by node
+func (p Pin) Check(ctx context.Context, client ChainReader) (*big.Int, error) {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get chain id")
+	}
+	if p.ChainID != nil && chainID.Cmp(p.ChainID) != 0 {
+		return nil, errors.From(ErrChainIDMismatch, logan.F{
+			"expected": p.ChainID.String(),
+			"actual":   chainID.String(),
+		})
+	}
+
+	if p.Genesis == (common.Hash{}) {
+		return chainID, nil
+	}
+
+	genesis, err := client.HeaderByNumber(ctx, big.NewInt(0))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get genesis header")
+	}
+	if genesis.Hash() != p.Genesis {
+		return nil, errors.From(ErrGenesisMismatch, logan.F{
+			"expected": p.Genesis.Hex(),
+			"actual":   genesis.Hash().Hex(),
+		})
+	}
+
+	return chainID, nil
+}
+
+// isSet returns true if there is anything to check
+func (p Pin) isSet() bool {
+	return p.ChainID != nil || p.Genesis != (common.Hash{})
+}
+
+// IsMismatch returns true if err means node is on another network
+func IsMismatch(err error) bool {
+	cause := errors.Cause(err)
+	return cause == ErrChainIDMismatch || cause == ErrGenesisMismatch
+}
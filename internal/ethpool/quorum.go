@@ -0,0 +1,46 @@
+package ethpool
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// ErrNoQuorum is returned if nodes returned different results and none of them is backed by enough nodes
+var ErrNoQuorum = errors.New("nodes did not reach quorum")
+
+// Size returns number of configured nodes, connected or not
+func (c *Client) Size() int {
+	return len(c.nodes)
+}
+
+// receiptFingerprint hashes parts of receipt withdrawal verification relies on. Every field is encoded
+// with fixed width, so different receipts can't produce the same preimage.
+func receiptFingerprint(receipt *types.Receipt) common.Hash {
+	data := [][]byte{
+		uint64Bytes(receipt.Status),
+		receipt.BlockHash.Bytes(),
+	}
+	for _, log := range receipt.Logs {
+		data = append(data, log.Address.Bytes(), []byte{byte(len(log.Topics))})
+		for _, topic := range log.Topics {
+			data = append(data, topic.Bytes())
+		}
+		removed := byte(0)
+		if log.Removed {
+			removed = 1
+		}
+		data = append(data, crypto.Keccak256(log.Data), uint64Bytes(uint64(log.Index)), []byte{removed})
+	}
+
+	return crypto.Keccak256Hash(data...)
+}
+
+func uint64Bytes(value uint64) []byte {
+	result := make([]byte, 8)
+	binary.BigEndian.PutUint64(result, value)
+	return result
+}
@@ -0,0 +1,113 @@
+package fees
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/url"
+	"strings"
+
+	"github.com/tokend/erc20-withdraw-svc/internal/config"
+	"github.com/tokend/erc20-withdraw-svc/internal/horizon/client"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	"gitlab.com/tokend/go/amount"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+// weiDecimals is number of fractional digits of ETH
+const weiDecimals = 18
+
+// RateSource provides price of one ETH in whole tokens
+type RateSource interface {
+	Rate(ctx context.Context) (*big.Rat, error)
+}
+
+// NewRateSource creates rate source configured for the asset fee, returns nil if fee is not gas indexed
+func NewRateSource(cfg config.AssetFee, horizon client.Interface) RateSource {
+	switch cfg.RateSource {
+	case config.RateSourceFile:
+		return &fileRate{path: cfg.RatePath}
+	case config.RateSourceKeyValue:
+		return &keyValueRate{horizon: horizon, key: cfg.RateKey}
+	default:
+		return nil
+	}
+}
+
+// GasFee converts cost of gas into token units, rounding up so gas is always covered
+func GasFee(gas uint64, gasPrice *big.Int, rate *big.Rat, decimals uint32) *big.Int {
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(gas), gasPrice)
+	num := cost.Mul(cost, rate.Num())
+	num.Mul(num, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	den := new(big.Int).Mul(rate.Denom(), new(big.Int).Exp(big.NewInt(10), big.NewInt(weiDecimals), nil))
+
+	result, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() != 0 {
+		result.Add(result, big.NewInt(1))
+	}
+
+	return result
+}
+
+// fileRate reads rate as a decimal number from file on every call, so it can be updated without restart
+type fileRate struct {
+	path string
+}
+
+func (r *fileRate) Rate(ctx context.Context) (*big.Rat, error) {
+	raw, err := ioutil.ReadFile(r.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read rate file", logan.F{"path": r.path})
+	}
+
+	return parseRate(strings.TrimSpace(string(raw)))
+}
+
+// keyValueRate reads rate from TokenD key value entry, string value is a decimal number,
+// integer one is an amount with TokenD precision
+type keyValueRate struct {
+	horizon client.Interface
+	key     string
+}
+
+func (r *keyValueRate) Rate(ctx context.Context) (*big.Rat, error) {
+	fields := logan.F{"key": r.key}
+	resp, err := r.horizon.GetContext(ctx, "/v3/key_values/"+url.PathEscape(r.key))
+	if err != nil {
+		if client.IsNotFound(err) {
+			return nil, errors.Wrap(err, "rate key value entry is not set", fields)
+		}
+		return nil, errors.Wrap(err, "failed to get key value entry", fields)
+	}
+
+	var entry regources.KeyValueEntryResponse
+	if err := json.Unmarshal(resp, &entry); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal key value entry", fields)
+	}
+
+	value := entry.Data.Attributes.Value
+	switch {
+	case value.Str != nil:
+		return parseRate(*value.Str)
+	case value.U64 != nil:
+		return new(big.Rat).SetFrac(new(big.Int).SetUint64(*value.U64), big.NewInt(amount.One)), nil
+	case value.U32 != nil:
+		return new(big.Rat).SetFrac(big.NewInt(int64(*value.U32)), big.NewInt(amount.One)), nil
+	default:
+		return nil, errors.From(errors.New("key value entry has no value"), fields)
+	}
+}
+
+func parseRate(raw string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(raw)
+	if !ok {
+		return nil, errors.From(errors.New("failed to parse rate"), logan.F{"raw": raw})
+	}
+	if rate.Sign() <= 0 {
+		return nil, errors.From(errors.New("rate must be positive"), logan.F{"raw": raw})
+	}
+
+	return rate, nil
+}
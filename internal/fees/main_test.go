@@ -0,0 +1,37 @@
+package fees
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasFee(t *testing.T) {
+	gwei := big.NewInt(1000000000)
+
+	t.Run("exact", func(t *testing.T) {
+		// 50000 gas * 20 gwei = 0.001 ETH, at 2000 tokens per ETH is 2 tokens
+		fee := GasFee(50000, new(big.Int).Mul(big.NewInt(20), gwei), big.NewRat(2000, 1), 6)
+		assert.Equal(t, big.NewInt(2000000), fee)
+	})
+
+	t.Run("rounds up", func(t *testing.T) {
+		fee := GasFee(1, big.NewInt(1), big.NewRat(1, 1), 6)
+		assert.Equal(t, big.NewInt(1), fee)
+	})
+
+	t.Run("fractional rate", func(t *testing.T) {
+		rate, err := parseRate("0.5")
+		assert.NoError(t, err)
+		fee := GasFee(21000, gwei, rate, 18)
+		assert.Equal(t, big.NewInt(10500000000000), fee)
+	})
+}
+
+func TestParseRate(t *testing.T) {
+	for _, raw := range []string{"", "abc", "0", "-1"} {
+		_, err := parseRate(raw)
+		assert.Error(t, err, raw)
+	}
+}
@@ -0,0 +1,172 @@
+package hold
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tokend/erc20-withdraw-svc/internal/storage"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// State is a state of held withdrawal request
+type State string
+
+const (
+	// StateHeld means request is waiting for manual decision
+	StateHeld State = "held"
+	// StateApproved means request was approved by reviewer and should be sent
+	StateApproved State = "approved"
+	// StateRejected means request was rejected by reviewer and should be rejected in TokenD
+	StateRejected State = "rejected"
+)
+
+var (
+	ErrNotFound       = errors.New("held request not found")
+	ErrAlreadyDecided = errors.New("decision on held request was already made")
+)
+
+// Request is withdrawal request held for manual review
+type Request struct {
+	ID      string    `json:"id"`
+	Asset   string    `json:"asset"`
+	Address string    `json:"address"`
+	Amount  string    `json:"amount"`
+	Reason  string    `json:"reason"`
+	HeldAt  time.Time `json:"held_at"`
+	State   State     `json:"state"`
+
+	DecidedBy string     `json:"decided_by,omitempty"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+	Comment   string     `json:"comment,omitempty"`
+}
+
+// Store keeps held withdrawal requests persisted across restarts
+type Store struct {
+	file     *storage.File
+	mu       sync.RWMutex
+	requests map[string]Request
+}
+
+// New creates store persisted in file, loading previously held requests
+func New(file *storage.File) (*Store, error) {
+	requests := make(map[string]Request)
+	if err := file.Load(&requests); err != nil {
+		return nil, errors.Wrap(err, "failed to load held requests")
+	}
+
+	return &Store{
+		file:     file,
+		requests: requests,
+	}, nil
+}
+
+// Hold puts request on hold, holding the same request twice keeps the first record
+func (s *Store) Hold(request Request) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.requests[request.ID]; ok {
+		return nil
+	}
+
+	request.State = StateHeld
+	if request.HeldAt.IsZero() {
+		request.HeldAt = time.Now().UTC()
+	}
+	s.requests[request.ID] = request
+
+	if err := s.file.Save(s.requests); err != nil {
+		delete(s.requests, request.ID)
+		return errors.Wrap(err, "failed to persist held request")
+	}
+
+	return nil
+}
+
+// Decide records reviewer's decision on held request
+func (s *Store) Decide(id string, approve bool, reviewer, comment string) (*Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	request, ok := s.requests[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if request.State != StateHeld {
+		return nil, ErrAlreadyDecided
+	}
+
+	previous := request
+	now := time.Now().UTC()
+	request.State = StateRejected
+	if approve {
+		request.State = StateApproved
+	}
+	request.DecidedBy = reviewer
+	request.DecidedAt = &now
+	request.Comment = comment
+	s.requests[id] = request
+
+	if err := s.file.Save(s.requests); err != nil {
+		s.requests[id] = previous
+		return nil, errors.Wrap(err, "failed to persist decision")
+	}
+
+	return &request, nil
+}
+
+// Get returns held request by id
+func (s *Store) Get(id string) (*Request, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	request, ok := s.requests[id]
+	if !ok {
+		return nil, false
+	}
+
+	return &request, true
+}
+
+// Prune removes requests which are not pending in TokenD anymore, store is persisted once for all of them
+func (s *Store) Prune(ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := make(map[string]Request, len(ids))
+	for _, id := range ids {
+		if value, ok := s.requests[id]; ok {
+			removed[id] = value
+			delete(s.requests, id)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	if err := s.file.Save(s.requests); err != nil {
+		for id, value := range removed {
+			s.requests[id] = value
+		}
+		return errors.Wrap(err, "failed to persist held requests")
+	}
+
+	return nil
+}
+
+// List returns all known requests ordered by the time they were held
+func (s *Store) List() []Request {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Request, 0, len(s.requests))
+	for _, request := range s.requests {
+		result = append(result, request)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].HeldAt.Before(result[j].HeldAt)
+	})
+
+	return result
+}
@@ -0,0 +1,68 @@
+package hold
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tokend/erc20-withdraw-svc/internal/storage"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hold")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := New(storage.NewFile(dir, "held.json"))
+	assert.NoError(t, err)
+	heldAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, store.Hold(Request{ID: "2", Asset: "TKN", Amount: "20", HeldAt: heldAt.Add(time.Hour)}))
+	assert.NoError(t, store.Hold(Request{ID: "1", Asset: "TKN", Amount: "10", HeldAt: heldAt}))
+	// holding the same request again keeps the first record
+	assert.NoError(t, store.Hold(Request{ID: "1", Asset: "TKN", Amount: "99"}))
+
+	request, ok := store.Get("1")
+	if assert.True(t, ok) {
+		assert.Equal(t, StateHeld, request.State)
+		assert.Equal(t, "10", request.Amount)
+	}
+	_, ok = store.Get("3")
+	assert.False(t, ok)
+
+	requests := store.List()
+	if assert.Len(t, requests, 2) {
+		assert.Equal(t, "1", requests[0].ID)
+		assert.Equal(t, "2", requests[1].ID)
+	}
+
+	approved, err := store.Decide("1", true, "alice", "checked")
+	assert.NoError(t, err)
+	assert.Equal(t, StateApproved, approved.State)
+	assert.Equal(t, "alice", approved.DecidedBy)
+	assert.NotNil(t, approved.DecidedAt)
+	rejected, err := store.Decide("2", false, "bob", "")
+	assert.NoError(t, err)
+	assert.Equal(t, StateRejected, rejected.State)
+
+	// decision is final
+	_, err = store.Decide("1", false, "bob", "")
+	assert.Equal(t, ErrAlreadyDecided, err)
+	_, err = store.Decide("3", true, "alice", "")
+	assert.Equal(t, ErrNotFound, err)
+
+	// decisions are persisted, so they are seen after restart
+	store, err = New(storage.NewFile(dir, "held.json"))
+	assert.NoError(t, err)
+	request, ok = store.Get("1")
+	if assert.True(t, ok) {
+		assert.Equal(t, StateApproved, request.State)
+		assert.Equal(t, "alice", request.DecidedBy)
+		assert.Equal(t, "checked", request.Comment)
+	}
+	request, ok = store.Get("2")
+	if assert.True(t, ok) {
+		assert.Equal(t, StateRejected, request.State)
+	}
+}
@@ -1,7 +1,9 @@
 package client
 
 import (
+	"context"
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/path"
+	"github.com/tokend/erc20-withdraw-svc/internal/horizon/ratelimit"
 	"io"
 	"net/http"
 	"net/url"
@@ -14,52 +16,56 @@ type Interface interface {
 	Get(endpoint string) ([]byte, error)
 	Put(endpoint string, body io.Reader) ([]byte, error)
 	Post(endpoint string, body io.Reader) ([]byte, error)
+	GetContext(ctx context.Context, endpoint string) ([]byte, error)
+	PutContext(ctx context.Context, endpoint string, body io.Reader) ([]byte, error)
+	PostContext(ctx context.Context, endpoint string, body io.Reader) ([]byte, error)
 }
 
 type Client struct {
-	throttle <-chan time.Time
-	client   *http.Client
-	signer   keypair.Full
-	resolve  path.Resolver
+	limiter *ratelimit.Limiter
+	client  *http.Client
+	signer  keypair.Full
+	resolve path.Resolver
+	// timeout limits every request, including time spent waiting for rate limiter and retries, zero means no limit
+	timeout time.Duration
+	backoff Backoff
 }
 
 func New(client *http.Client, base *url.URL) *Client {
 	return &Client{
-		client:   client,
-		resolve:  path.NewResolver(base),
-		throttle: throttle(),
+		client:  client,
+		resolve: path.NewResolver(base),
+		limiter: ratelimit.New(ratelimit.DefaultReads, ratelimit.DefaultSubmits),
 	}
 }
 
 func (c *Client) WithSigner(signer keypair.Full) *Client {
-	return &Client{
-		client:   c.client,
-		signer:   signer,
-		resolve:  c.resolve,
-		throttle: c.throttle,
-	}
+	result := *c
+	result.signer = signer
+	return &result
 }
 
-func (c *Client) Resolve() path.Resolver {
-	return c.resolve
+// WithTimeout returns client which aborts requests taking longer than timeout
+func (c *Client) WithTimeout(timeout time.Duration) *Client {
+	result := *c
+	result.timeout = timeout
+	return &result
 }
 
-func throttle() chan time.Time {
-	burst := 2 << 10
-	ch := make(chan time.Time, burst)
+// WithRetry returns client which retries requests failed for transient reasons with backoff
+func (c *Client) WithRetry(backoff Backoff) *Client {
+	result := *c
+	result.backoff = backoff
+	return &result
+}
 
-	go func() {
-		tick := time.Tick(1 * time.Second)
-		// prefill buffer
-		for i := 0; i < burst; i++ {
-			ch <- time.Now()
-		}
-		for {
-			select {
-			case ch <- <-tick:
-			default:
-			}
-		}
-	}()
-	return ch
+// WithLimiter returns client sharing rate limiter, so requests of all its copies fit into the same budgets
+func (c *Client) WithLimiter(limiter *ratelimit.Limiter) *Client {
+	result := *c
+	result.limiter = limiter
+	return &result
+}
+
+func (c *Client) Resolve() path.Resolver {
+	return c.resolve
 }
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// Error is an unsuccessful response of Horizon
+type Error struct {
+	Status int
+	Body   []byte
+}
+
+func (e *Error) Error() string {
+	return http.StatusText(e.Status)
+}
+
+// IsTransient returns true if request failed for reason likely to pass by itself, e.g. Horizon being overloaded,
+// restarted or unreachable, so it is worth to try again later
+func IsTransient(err error) bool {
+	switch cause := errors.Cause(err).(type) {
+	case nil:
+		return false
+	case *Error:
+		return isTransientStatus(cause.Status)
+	case *url.Error:
+		// request canceled by caller is not going to succeed on its own
+		return cause.Err != context.Canceled
+	case net.Error:
+		return true
+	default:
+		return cause == context.DeadlineExceeded || cause == io.ErrUnexpectedEOF
+	}
+}
+
+// IsAuth returns true if Horizon refused to serve request signed by client signer
+func IsAuth(err error) bool {
+	cause, ok := errors.Cause(err).(*Error)
+	return ok && (cause.Status == http.StatusUnauthorized || cause.Status == http.StatusForbidden)
+}
+
+// IsNotFound returns true if requested resource does not exist
+func IsNotFound(err error) bool {
+	cause, ok := errors.Cause(err).(*Error)
+	return ok && cause.Status == http.StatusNotFound
+}
+
+func isTransientStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
@@ -1,9 +1,11 @@
 package client
 
 import (
+	"context"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"gitlab.com/distributed_lab/logan/v3"
 	"gitlab.com/distributed_lab/logan/v3/errors"
@@ -12,70 +14,112 @@ import (
 )
 
 func (c *Client) Get(endpoint string) ([]byte, error) {
-	u, err := c.resolve.URL(endpoint)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to resolve url")
-	}
-	r, err := http.NewRequest("GET", u, nil)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to prepare request")
-	}
-
-	return c.performRequest(r)
+	return c.GetContext(context.Background(), endpoint)
 }
 
 func (c *Client) Put(endpoint string, body io.Reader) ([]byte, error) {
-	u, err := c.resolve.URL(endpoint)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to resolve url")
-	}
-	r, err := http.NewRequest("PUT", u, body)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to prepare request")
-	}
-
-	return c.performRequest(r)
+	return c.PutContext(context.Background(), endpoint, body)
 }
 
 func (c *Client) Post(endpoint string, body io.Reader) ([]byte, error) {
+	return c.PostContext(context.Background(), endpoint, body)
+}
+
+// GetContext performs GET request, which is aborted once ctx is done
+func (c *Client) GetContext(ctx context.Context, endpoint string) ([]byte, error) {
+	return c.request(ctx, "GET", endpoint, nil)
+}
+
+// PutContext performs PUT request, which is aborted once ctx is done
+func (c *Client) PutContext(ctx context.Context, endpoint string, body io.Reader) ([]byte, error) {
+	return c.request(ctx, "PUT", endpoint, body)
+}
+
+// PostContext performs POST request, which is aborted once ctx is done
+func (c *Client) PostContext(ctx context.Context, endpoint string, body io.Reader) ([]byte, error) {
+	return c.request(ctx, "POST", endpoint, body)
+}
+
+func (c *Client) request(ctx context.Context, method, endpoint string, body io.Reader) ([]byte, error) {
 	u, err := c.resolve.URL(endpoint)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to resolve url")
 	}
-	r, err := http.NewRequest("POST", u, body)
+	r, err := http.NewRequest(method, u, body)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to prepare request")
 	}
 
-	return c.performRequest(r)
+	return c.performRequest(r.WithContext(ctx))
 }
+
+// Do performs request within its context, limited by client timeout. Request failed for transient reason
+// is retried with backoff, if it is safe to send it again.
 func (c *Client) Do(r *http.Request) (int, []byte, error) {
-	<-c.throttle
+	ctx := r.Context()
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+
+	for attempt := 1; ; attempt++ {
+		status, header, body, err := c.attempt(r)
+		if attempt >= c.backoff.Attempts || !retryable(r, status, err) {
+			return status, body, err
+		}
+		if r.GetBody != nil {
+			if r.Body, err = r.GetBody(); err != nil {
+				return 0, nil, errors.Wrap(err, "failed to reset request body")
+			}
+		}
+
+		select {
+		case <-time.After(c.backoff.delay(attempt, retryAfter(header))):
+		case <-ctx.Done():
+			// result of the last attempt tells more than cancellation
+			return status, body, err
+		}
+	}
+}
+
+func (c *Client) attempt(r *http.Request) (int, http.Header, []byte, error) {
+	bucket := c.limiter.For(r)
+	if err := bucket.Wait(r.Context()); err != nil {
+		return 0, nil, nil, errors.Wrap(err, "request aborted by rate limiter")
+	}
 	// ensure content-type just in case
 	r.Header.Set("content-type", "application/json")
 
 	if c.signer != nil {
 		err := signcontrol.SignRequest(r, depkeypair.MustParse(c.signer.Seed()))
 		if err != nil {
-			return 0, nil, errors.Wrap(err, "failed to sign request")
+			return 0, nil, nil, errors.Wrap(err, "failed to sign request")
 		}
 	}
 
 	response, err := c.client.Do(r)
 	if err != nil {
-		return 0, nil, errors.Wrap(err, "failed to perform http request")
+		return 0, nil, nil, errors.Wrap(err, "failed to perform http request")
 	}
 
 	defer response.Body.Close()
 
 	respBB, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		return 0, nil, errors.Wrap(err, "Failed to read response body", logan.F{
+		return 0, nil, nil, errors.Wrap(err, "Failed to read response body", logan.F{
 			"status_code": response.StatusCode,
 		})
 	}
 
-	return response.StatusCode, respBB, nil
+	if response.StatusCode == http.StatusTooManyRequests {
+		bucket.Throttled(retryAfter(response.Header))
+	} else {
+		bucket.Succeeded()
+	}
+
+	return response.StatusCode, response.Header, respBB, nil
 }
 
 func (c *Client) performRequest(r *http.Request) ([]byte, error) {
@@ -92,7 +136,7 @@ func handleResponse(resp []byte, code int) ([]byte, error) {
 		return resp, nil
 	}
 
-	return resp, errors.New(http.StatusText(code))
+	return resp, &Error{Status: code, Body: resp}
 }
 
 func isStatusCodeSuccessful(code int) bool {
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_GetContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/hang" {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	base, _ := url.Parse(server.URL)
+	c := New(http.DefaultClient, base)
+
+	t.Run("success", func(t *testing.T) {
+		resp, err := c.WithTimeout(time.Second).GetContext(context.Background(), "/ok")
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", string(resp))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		start := time.Now()
+		_, err := c.WithTimeout(100 * time.Millisecond).Get("/hang")
+		assert.Error(t, err)
+		assert.True(t, time.Since(start) < 5*time.Second)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+		start := time.Now()
+		_, err := c.GetContext(ctx, "/hang")
+		assert.Error(t, err)
+		assert.True(t, time.Since(start) < 5*time.Second)
+	})
+
+	t.Run("canceled before throttle", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := c.GetContext(ctx, "/ok")
+		assert.Error(t, err)
+	})
+}
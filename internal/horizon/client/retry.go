@@ -0,0 +1,95 @@
+package client
+
+import (
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// Backoff configures retries of requests failed for transient reasons
+type Backoff struct {
+	// Attempts is the max number of attempts including the first one, request is not retried if it is below 2
+	Attempts int
+	// Min is a delay before the first retry, it doubles on every next one up to Max
+	Min time.Duration
+	Max time.Duration
+}
+
+// delay returns jittered delay before retry following attempt, delay requested by Horizon takes precedence if longer
+func (b Backoff) delay(attempt int, retryAfter time.Duration) time.Duration {
+	delay := b.Min
+	for i := 1; i < attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if delay > b.Max {
+		delay = b.Max
+	}
+	// half of delay is randomized, so workers failed at once don't retry at once
+	if half := int64(delay / 2); half > 0 {
+		delay = time.Duration(half + rand.Int63n(half+1))
+	}
+	if retryAfter > delay {
+		return retryAfter
+	}
+	return delay
+}
+
+// retryable returns true if request may be sent again after it failed with err or got status.
+// GET is idempotent, so it is retried on any transient failure, while request changing state is only retried
+// if it is known to be not processed: connection was never established or Horizon rejected it by rate limit.
+func retryable(r *http.Request, status int, err error) bool {
+	if r.Context().Err() != nil {
+		return false
+	}
+	if r.Body != nil && r.GetBody == nil {
+		// body is consumed and can't be sent again
+		return false
+	}
+
+	if r.Method == http.MethodGet {
+		if err != nil {
+			return IsTransient(err)
+		}
+		return isTransientStatus(status)
+	}
+
+	if err != nil {
+		return isNotDelivered(err)
+	}
+	return status == http.StatusTooManyRequests
+}
+
+// isNotDelivered returns true if transport error happened before request was sent
+func isNotDelivered(err error) bool {
+	urlErr, ok := errors.Cause(err).(*url.Error)
+	if !ok {
+		return false
+	}
+	opErr, ok := urlErr.Err.(*net.OpError)
+	return ok && opErr.Op == "dial"
+}
+
+// retryAfter parses Retry-After header set in seconds or as a date, zero is returned if it is absent or invalid
+func retryAfter(header http.Header) time.Duration {
+	raw := header.Get("Retry-After")
+	if raw == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(raw); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
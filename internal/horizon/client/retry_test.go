@@ -0,0 +1,145 @@
+package client
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failing serves failures statuses first and then succeeds, echoing request body
+func failing(statuses ...int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(atomic.AddInt32(&calls, 1))
+		if call <= len(statuses) {
+			if statuses[call-1] == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "1")
+			}
+			w.WriteHeader(statuses[call-1])
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	return server, &calls
+}
+
+func newRetrying(server *httptest.Server) *Client {
+	base, _ := url.Parse(server.URL)
+	return New(http.DefaultClient, base).WithRetry(Backoff{
+		Attempts: 3,
+		Min:      10 * time.Millisecond,
+		Max:      50 * time.Millisecond,
+	})
+}
+
+func TestClient_Retry(t *testing.T) {
+	t.Run("get retried", func(t *testing.T) {
+		server, calls := failing(http.StatusServiceUnavailable, http.StatusBadGateway)
+		defer server.Close()
+
+		_, err := newRetrying(server).Get("/")
+		assert.NoError(t, err)
+		assert.EqualValues(t, 3, atomic.LoadInt32(calls))
+	})
+
+	t.Run("get attempts exhausted", func(t *testing.T) {
+		server, calls := failing(http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+		defer server.Close()
+
+		_, err := newRetrying(server).Get("/")
+		assert.True(t, IsTransient(err))
+		assert.EqualValues(t, 3, atomic.LoadInt32(calls))
+	})
+
+	t.Run("retry after", func(t *testing.T) {
+		server, calls := failing(http.StatusTooManyRequests)
+		defer server.Close()
+
+		start := time.Now()
+		_, err := newRetrying(server).Get("/")
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, atomic.LoadInt32(calls))
+		assert.True(t, time.Since(start) >= time.Second)
+	})
+
+	t.Run("not found is not retried", func(t *testing.T) {
+		server, calls := failing(http.StatusNotFound)
+		defer server.Close()
+
+		_, err := newRetrying(server).Get("/")
+		assert.True(t, IsNotFound(err))
+		assert.False(t, IsTransient(err))
+		assert.EqualValues(t, 1, atomic.LoadInt32(calls))
+	})
+
+	t.Run("auth", func(t *testing.T) {
+		server, _ := failing(http.StatusUnauthorized)
+		defer server.Close()
+
+		_, err := newRetrying(server).Get("/")
+		assert.True(t, IsAuth(err))
+		assert.False(t, IsTransient(err))
+	})
+
+	t.Run("post not retried after server error", func(t *testing.T) {
+		server, calls := failing(http.StatusInternalServerError)
+		defer server.Close()
+
+		_, err := newRetrying(server).Post("/", bytes.NewBufferString("body"))
+		assert.True(t, IsTransient(err))
+		assert.EqualValues(t, 1, atomic.LoadInt32(calls))
+	})
+
+	t.Run("post retried after rate limit", func(t *testing.T) {
+		server, calls := failing(http.StatusTooManyRequests)
+		defer server.Close()
+
+		resp, err := newRetrying(server).Post("/", bytes.NewBufferString("body"))
+		assert.NoError(t, err)
+		assert.Equal(t, "body", string(resp))
+		assert.EqualValues(t, 2, atomic.LoadInt32(calls))
+	})
+
+	t.Run("post not delivered", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if !assert.NoError(t, err) {
+			return
+		}
+		base, _ := url.Parse("http://" + listener.Addr().String())
+		listener.Close()
+
+		_, err = New(http.DefaultClient, base).Post("/", bytes.NewBufferString("body"))
+		assert.True(t, isNotDelivered(err))
+		assert.True(t, IsTransient(err))
+	})
+}
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Min: 100 * time.Millisecond, Max: time.Second}
+	for attempt := 1; attempt < 10; attempt++ {
+		delay := b.delay(attempt, 0)
+		assert.True(t, delay >= b.Min/2 && delay <= b.Max, "delay %s of attempt %d", delay, attempt)
+	}
+	assert.True(t, b.delay(10, 0) >= b.Max/2)
+	assert.Equal(t, 5*time.Second, b.delay(1, 5*time.Second))
+}
+
+func TestRetryAfter(t *testing.T) {
+	header := http.Header{}
+	assert.Equal(t, time.Duration(0), retryAfter(header))
+	header.Set("Retry-After", "3")
+	assert.Equal(t, 3*time.Second, retryAfter(header))
+	header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, retryAfter(header) > 50*time.Second)
+	header.Set("Retry-After", "soon")
+	assert.Equal(t, time.Duration(0), retryAfter(header))
+}
@@ -0,0 +1,100 @@
+package fake
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+// paginate returns page of ids after `page[cursor]` and links to it, greater tells whether one id goes after another
+func paginate(u *url.URL, ids []string, greater func(a, b string) bool) ([]string, *regources.Links, error) {
+	query := u.Query()
+	limit := DefaultPageLimit
+	if raw := query.Get("page[limit]"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return nil, nil, errors.New("invalid page limit")
+		}
+	}
+	cursor := query.Get("page[cursor]")
+
+	page := make([]string, 0, limit)
+	for _, id := range ids {
+		if len(page) == limit {
+			break
+		}
+		if cursor == "" || greater(id, cursor) {
+			page = append(page, id)
+		}
+	}
+
+	if len(page) > 0 {
+		cursor = page[len(page)-1]
+	}
+	next := url.URL{Path: u.Path}
+	query.Set("page[cursor]", cursor)
+	next.RawQuery = query.Encode()
+	return page, &regources.Links{
+		Self: u.RequestURI(),
+		Next: next.RequestURI(),
+	}, nil
+}
+
+// includes returns true if query asks to include resource
+func includes(query url.Values, resource string) bool {
+	for _, include := range query["include"] {
+		if include == resource {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		panic(errors.Wrap(err, "failed to write response"))
+	}
+}
+
+type errorObject struct {
+	Title  string      `json:"title"`
+	Detail string      `json:"detail,omitempty"`
+	Status string      `json:"status"`
+	Meta   interface{} `json:"meta,omitempty"`
+}
+
+func writeError(w http.ResponseWriter, status int, detail string) {
+	writeJSON(w, status, map[string][]errorObject{
+		"errors": {{
+			Title:  http.StatusText(status),
+			Detail: detail,
+			Status: strconv.Itoa(status),
+		}},
+	})
+}
+
+// writeTxFailure responds with result codes of transaction core has not applied
+func writeTxFailure(w http.ResponseWriter, envelope, code string, operations []string) {
+	meta := map[string]interface{}{
+		"envelope": envelope,
+		"result_codes": map[string]interface{}{
+			"transaction": code,
+			"operations":  operations,
+		},
+	}
+	writeJSON(w, http.StatusBadRequest, map[string][]errorObject{
+		"errors": {{
+			Title:  http.StatusText(http.StatusBadRequest),
+			Detail: "Transaction failed",
+			Status: strconv.Itoa(http.StatusBadRequest),
+			Meta:   meta,
+		}},
+	})
+}
@@ -0,0 +1,402 @@
+package fake
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	"gitlab.com/tokend/go/network"
+	"gitlab.com/tokend/go/xdr"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+// States of reviewable request, as Horizon reports them in `state_i`
+const (
+	StatePending             int32 = 1
+	StateCanceled            int32 = 2
+	StateApproved            int32 = 3
+	StateRejected            int32 = 4
+	StatePermanentlyRejected int32 = 5
+)
+
+// DefaultPageLimit is a page size used when request does not set one
+const DefaultPageLimit = 15
+
+var stateNames = map[int32]string{
+	StatePending:             "pending",
+	StateCanceled:            "canceled",
+	StateApproved:            "approved",
+	StateRejected:            "rejected",
+	StatePermanentlyRejected: "permanently_rejected",
+}
+
+// Withdraw describes withdraw request created by user
+type Withdraw struct {
+	Asset          string
+	Amount         regources.Amount
+	CreatorDetails string
+	// Tasks are pending tasks request is created with
+	Tasks uint32
+}
+
+// Request is a state of withdraw request
+type Request struct {
+	ID              string
+	Hash            string
+	Asset           string
+	Reviewer        string
+	Amount          regources.Amount
+	CreatorDetails  string
+	PendingTasks    uint32
+	AllTasks        uint32
+	State           int32
+	RejectReason    string
+	ExternalDetails []json.RawMessage
+}
+
+// Horizon is an in-process Horizon serving endpoints withdraw service relies on.
+// It keeps assets and withdraw requests in memory and applies review operations of submitted transactions
+// to task flags of requests the way core does, while signatures and balances are not checked.
+type Horizon struct {
+	*httptest.Server
+	passphrase string
+
+	mu       sync.Mutex
+	assets   map[string]regources.Asset
+	requests map[string]*Request
+	order    []string
+	calls    []string
+	// txs are applied transactions by hash
+	txs    map[string]regources.Transaction
+	faults []Fault
+}
+
+// Fault replaces response to transaction submission, e.g. with timeout Horizon responds with
+// while transaction is still applied by core
+type Fault struct {
+	Status int
+	// Apply tells whether transaction is applied regardless of response
+	Apply bool
+}
+
+// New starts Horizon of network with passphrase, it must be closed once not needed
+func New(passphrase string) *Horizon {
+	h := &Horizon{
+		passphrase: passphrase,
+		assets:     make(map[string]regources.Asset),
+		requests:   make(map[string]*Request),
+		txs:        make(map[string]regources.Transaction),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v3/info", h.info)
+	mux.HandleFunc("/v3/assets", h.assetList)
+	mux.HandleFunc("/v3/assets/", h.assetByID)
+	mux.HandleFunc("/v3/create_withdraw_requests", h.requestList)
+	mux.HandleFunc("/v3/create_withdraw_requests/", h.requestByID)
+	mux.HandleFunc("/v3/transactions", h.submit)
+	mux.HandleFunc("/v3/transactions/", h.transactionByID)
+	h.Server = httptest.NewServer(h.record(mux))
+	return h
+}
+
+// URL returns endpoint of Horizon
+func (h *Horizon) URL() *url.URL {
+	endpoint, err := url.Parse(h.Server.URL)
+	if err != nil {
+		panic(errors.Wrap(err, "failed to parse server url"))
+	}
+	return endpoint
+}
+
+// AddAsset adds asset or replaces existing one with the same code
+func (h *Horizon) AddAsset(asset regources.Asset) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	asset.Type = regources.ASSETS
+	h.assets[asset.ID] = asset
+}
+
+// CreateWithdraw creates pending withdraw request reviewed by asset owner and returns its id
+func (h *Horizon) CreateWithdraw(withdraw Withdraw) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	asset, ok := h.assets[withdraw.Asset]
+	if !ok {
+		panic(errors.Errorf("asset %s does not exist", withdraw.Asset))
+	}
+	id := strconv.Itoa(len(h.order) + 1)
+	hash := sha256.Sum256([]byte(h.passphrase + id))
+	h.requests[id] = &Request{
+		ID:             id,
+		Hash:           hex.EncodeToString(hash[:]),
+		Asset:          withdraw.Asset,
+		Reviewer:       asset.Relationships.Owner.Data.ID,
+		Amount:         withdraw.Amount,
+		CreatorDetails: withdraw.CreatorDetails,
+		PendingTasks:   withdraw.Tasks,
+		AllTasks:       withdraw.Tasks,
+		State:          StatePending,
+	}
+	h.order = append(h.order, id)
+	return id
+}
+
+// Request returns current state of withdraw request
+func (h *Horizon) Request(id string) Request {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	request, ok := h.requests[id]
+	if !ok {
+		panic(errors.Errorf("request %s does not exist", id))
+	}
+	result := *request
+	result.ExternalDetails = append([]json.RawMessage{}, request.ExternalDetails...)
+	return result
+}
+
+// FailSubmits makes next submissions respond with faults, one fault per submission
+func (h *Horizon) FailSubmits(faults ...Fault) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.faults = append(h.faults, faults...)
+}
+
+// Applied returns number of transactions applied so far
+func (h *Horizon) Applied() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.txs)
+}
+
+// Calls returns URIs of all GET requests served so far
+func (h *Horizon) Calls() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string{}, h.calls...)
+}
+
+func (h *Horizon) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			h.mu.Lock()
+			h.calls = append(h.calls, r.URL.RequestURI())
+			h.mu.Unlock()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *Horizon) info(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	writeJSON(w, http.StatusOK, regources.HorizonStateResponse{
+		Data: regources.HorizonState{
+			Key: regources.Key{ID: "horizon-state", Type: regources.HORIZON_STATE},
+			Attributes: regources.HorizonStateAttributes{
+				CurrentTime:        now,
+				CurrentTimeUnix:    now.Unix(),
+				NetworkPassphrase:  h.passphrase,
+				Precision:          6,
+				TxExpirationPeriod: int64((7 * 24 * time.Hour).Seconds()),
+			},
+		},
+	})
+}
+
+func (h *Horizon) assetList(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	query := r.URL.Query()
+	codes := make([]string, 0, len(h.assets))
+	for code, asset := range h.assets {
+		if policy := query.Get("filter[policy]"); policy != "" {
+			mask, err := strconv.ParseInt(policy, 10, 32)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid policy filter")
+				return
+			}
+			if int32(asset.Attributes.Policies)&int32(mask) != int32(mask) {
+				continue
+			}
+		}
+		if owner := query.Get("filter[owner]"); owner != "" && owner != asset.Relationships.Owner.Data.ID {
+			continue
+		}
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	page, links, err := paginate(r.URL, codes, func(a, b string) bool { return a > b })
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	response := regources.AssetListResponse{Data: []regources.Asset{}, Links: links}
+	for _, code := range page {
+		response.Data = append(response.Data, h.assets[code])
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (h *Horizon) assetByID(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	asset, ok := h.assets[strings.TrimPrefix(r.URL.Path, "/v3/assets/")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "asset not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, regources.AssetResponse{Data: asset})
+}
+
+func (h *Horizon) requestList(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	query := r.URL.Query()
+	var ids []string
+	for _, id := range h.order {
+		ok, err := matches(h.requests[id], query)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if ok {
+			ids = append(ids, id)
+		}
+	}
+
+	page, links, err := paginate(r.URL, ids, func(a, b string) bool {
+		return len(a) > len(b) || len(a) == len(b) && a > b
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	response := regources.ReviewableRequestListResponse{Data: []regources.ReviewableRequest{}, Links: links}
+	for _, id := range page {
+		request := h.requests[id]
+		response.Data = append(response.Data, request.resource())
+		if includes(query, "request_details") {
+			response.Included.Add(request.details())
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (h *Horizon) requestByID(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	request, ok := h.requests[strings.TrimPrefix(r.URL.Path, "/v3/create_withdraw_requests/")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "request not found")
+		return
+	}
+	response := regources.ReviewableRequestResponse{Data: request.resource()}
+	if includes(r.URL.Query(), "request_details") {
+		response.Included.Add(request.details())
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (h *Horizon) submit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var body regources.SubmitTransactionBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	var envelope xdr.TransactionEnvelope
+	if err := xdr.SafeUnmarshalBase64(body.Tx, &envelope); err != nil {
+		writeTxFailure(w, body.Tx, "tx_malformed", nil)
+		return
+	}
+
+	hash, err := network.HashTransaction(&envelope.Tx, h.passphrase)
+	if err != nil {
+		writeTxFailure(w, body.Tx, "tx_malformed", nil)
+		return
+	}
+	id := hex.EncodeToString(hash[:])
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var fault *Fault
+	if len(h.faults) > 0 {
+		fault = &h.faults[0]
+		h.faults = h.faults[1:]
+		if !fault.Apply {
+			writeError(w, fault.Status, "transaction submission failed")
+			return
+		}
+	}
+
+	if _, ok := h.txs[id]; ok {
+		writeTxFailure(w, body.Tx, "tx_duplication", nil)
+		return
+	}
+	if maxTime := uint64(envelope.Tx.TimeBounds.MaxTime); maxTime != 0 && uint64(time.Now().Unix()) > maxTime {
+		writeTxFailure(w, body.Tx, "tx_too_late", nil)
+		return
+	}
+	// transaction is applied atomically, so every operation is checked before any of them is applied
+	codes := make([]string, len(envelope.Tx.Operations))
+	failed := false
+	for i, op := range envelope.Tx.Operations {
+		codes[i] = h.check(op)
+		failed = failed || codes[i] != opSuccess
+	}
+	if failed {
+		writeTxFailure(w, body.Tx, "tx_failed", codes)
+		return
+	}
+	for _, op := range envelope.Tx.Operations {
+		h.apply(op.Body.MustReviewRequestOp())
+	}
+
+	tx := regources.Transaction{
+		Key: regources.Key{ID: strconv.Itoa(len(h.txs) + 1), Type: regources.TRANSACTIONS},
+		Attributes: regources.TransactionAttributes{
+			CreatedAt:   time.Now().UTC(),
+			EnvelopeXdr: body.Tx,
+			Hash:        id,
+		},
+	}
+	h.txs[id] = tx
+	if fault != nil {
+		writeError(w, fault.Status, "transaction submission failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, regources.TransactionResponse{Data: tx})
+}
+
+// transactionByID serves applied transaction by hash
+func (h *Horizon) transactionByID(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	tx, ok := h.txs[strings.TrimPrefix(r.URL.Path, "/v3/transactions/")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, regources.TransactionResponse{Data: tx})
+}
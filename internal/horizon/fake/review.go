@@ -0,0 +1,136 @@
+package fake
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	"gitlab.com/tokend/go/xdr"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+const opSuccess = "op_success"
+
+// check returns result code operation would be applied with
+func (h *Horizon) check(op xdr.Operation) string {
+	review, ok := op.Body.GetReviewRequestOp()
+	if !ok {
+		return "op_not_supported"
+	}
+	request, ok := h.requests[strconv.FormatUint(uint64(review.RequestId), 10)]
+	if !ok || request.State != StatePending {
+		return "op_not_found"
+	}
+	if hex.EncodeToString(review.RequestHash[:]) != request.Hash {
+		return "op_hash_mismatched"
+	}
+
+	switch review.Action {
+	case xdr.ReviewRequestOpActionApprove:
+		if review.Reason != "" {
+			return "op_invalid_reason"
+		}
+	case xdr.ReviewRequestOpActionPermanentReject:
+		if review.Reason == "" {
+			return "op_invalid_reason"
+		}
+	default:
+		return "op_invalid_action"
+	}
+	return opSuccess
+}
+
+// apply applies checked review to request, approving it once no tasks are pending
+func (h *Horizon) apply(review xdr.ReviewRequestOp) {
+	request := h.requests[strconv.FormatUint(uint64(review.RequestId), 10)]
+	switch review.Action {
+	case xdr.ReviewRequestOpActionApprove:
+		request.PendingTasks |= uint32(review.ReviewDetails.TasksToAdd)
+		request.PendingTasks &^= uint32(review.ReviewDetails.TasksToRemove)
+		request.AllTasks |= uint32(review.ReviewDetails.TasksToAdd)
+		if review.ReviewDetails.ExternalDetails != "" {
+			request.ExternalDetails = append(request.ExternalDetails, json.RawMessage(review.ReviewDetails.ExternalDetails))
+		}
+		if request.PendingTasks == 0 {
+			request.State = StateApproved
+		}
+	case xdr.ReviewRequestOpActionPermanentReject:
+		request.State = StatePermanentlyRejected
+		request.RejectReason = string(review.Reason)
+	}
+}
+
+// matches returns true if request satisfies filters of query
+func matches(request *Request, query url.Values) (bool, error) {
+	if state := query.Get("filter[state]"); state != "" && state != strconv.Itoa(int(request.State)) {
+		return false, nil
+	}
+	if reviewer := query.Get("filter[reviewer]"); reviewer != "" && reviewer != request.Reviewer {
+		return false, nil
+	}
+	if asset := query.Get("filter[request_details.asset]"); asset != "" && asset != request.Asset {
+		return false, nil
+	}
+
+	tasks := []struct {
+		filter string
+		ok     func(mask uint32) bool
+	}{
+		{"filter[pending_tasks]", func(mask uint32) bool { return request.PendingTasks&mask == mask }},
+		{"filter[pending_tasks_any_of]", func(mask uint32) bool { return request.PendingTasks&mask != 0 }},
+		{"filter[pending_tasks_not_set]", func(mask uint32) bool { return request.PendingTasks&mask == 0 }},
+	}
+	for _, task := range tasks {
+		raw := query.Get(task.filter)
+		if raw == "" {
+			continue
+		}
+		mask, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return false, errors.Errorf("invalid %s", task.filter)
+		}
+		if !task.ok(uint32(mask)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (r *Request) resource() regources.ReviewableRequest {
+	external, err := json.Marshal(struct {
+		Data []json.RawMessage `json:"data"`
+	}{r.ExternalDetails})
+	if err != nil {
+		panic(errors.Wrap(err, "failed to marshal external details"))
+	}
+	detailsKey := r.details().Key
+	return regources.ReviewableRequest{
+		Key: regources.Key{ID: r.ID, Type: regources.REQUESTS},
+		Attributes: regources.ReviewableRequestAttributes{
+			AllTasks:        r.AllTasks,
+			ExternalDetails: regources.Details(external),
+			Hash:            r.Hash,
+			PendingTasks:    r.PendingTasks,
+			RejectReason:    r.RejectReason,
+			State:           stateNames[r.State],
+			StateI:          r.State,
+			XdrType:         xdr.ReviewableRequestTypeCreateWithdraw,
+		},
+		Relationships: regources.ReviewableRequestRelationships{
+			RequestDetails: &regources.Relation{Data: &detailsKey},
+			Reviewer:       &regources.Relation{Data: &regources.Key{ID: r.Reviewer, Type: regources.ACCOUNTS}},
+		},
+	}
+}
+
+func (r *Request) details() *regources.CreateWithdrawRequest {
+	return &regources.CreateWithdrawRequest{
+		Key: regources.Key{ID: r.ID, Type: regources.REQUEST_DETAILS_WITHDRAWAL},
+		Attributes: regources.CreateWithdrawRequestAttributes{
+			Amount:         r.Amount,
+			CreatorDetails: regources.Details(r.CreatorDetails),
+		},
+	}
+}
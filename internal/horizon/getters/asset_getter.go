@@ -5,6 +5,8 @@
 package getters
 
 import (
+	"context"
+
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/client"
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/page"
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/query"
@@ -15,9 +17,13 @@ import (
 
 type AssetPager interface {
 	Next() (*regources.AssetListResponse, error)
+	NextContext(ctx context.Context) (*regources.AssetListResponse, error)
 	Prev() (*regources.AssetListResponse, error)
+	PrevContext(ctx context.Context) (*regources.AssetListResponse, error)
 	Self() (*regources.AssetListResponse, error)
+	SelfContext(ctx context.Context) (*regources.AssetListResponse, error)
 	First() (*regources.AssetListResponse, error)
+	FirstContext(ctx context.Context) (*regources.AssetListResponse, error)
 }
 
 type AssetGetter interface {
@@ -31,7 +37,9 @@ type AssetGetter interface {
 	Page() page.Params
 
 	ByID(ID string) (*regources.AssetResponse, error)
+	ByIDContext(ctx context.Context, ID string) (*regources.AssetResponse, error)
 	List() (*regources.AssetListResponse, error)
+	ListContext(ctx context.Context) (*regources.AssetListResponse, error)
 }
 
 type AssetHandler interface {
@@ -85,8 +93,12 @@ func (g *defaultAssetHandler) Page() page.Params {
 }
 
 func (g *defaultAssetHandler) ByID(ID string) (*regources.AssetResponse, error) {
+	return g.ByIDContext(context.Background(), ID)
+}
+
+func (g *defaultAssetHandler) ByIDContext(ctx context.Context, ID string) (*regources.AssetResponse, error) {
 	result := &regources.AssetResponse{}
-	err := g.base.GetPage(query.AssetByID(ID), g.params.Includes, result)
+	err := g.base.GetPageContext(ctx, query.AssetByID(ID), g.params.Includes, result)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get record by id", logan.F{
 			"id": ID,
@@ -96,8 +108,12 @@ func (g *defaultAssetHandler) ByID(ID string) (*regources.AssetResponse, error)
 }
 
 func (g *defaultAssetHandler) List() (*regources.AssetListResponse, error) {
+	return g.ListContext(context.Background())
+}
+
+func (g *defaultAssetHandler) ListContext(ctx context.Context) (*regources.AssetListResponse, error) {
 	result := &regources.AssetListResponse{}
-	err := g.base.GetPage(query.AssetList(), g.params, result)
+	err := g.base.GetPageContext(ctx, query.AssetList(), g.params, result)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get records list", logan.F{
 			"query_params": g.params,
@@ -108,6 +124,10 @@ func (g *defaultAssetHandler) List() (*regources.AssetListResponse, error) {
 }
 
 func (g *defaultAssetHandler) Next() (*regources.AssetListResponse, error) {
+	return g.NextContext(context.Background())
+}
+
+func (g *defaultAssetHandler) NextContext(ctx context.Context) (*regources.AssetListResponse, error) {
 	if g.currentPageLinks == nil {
 		return nil, errors.New("Empty links")
 	}
@@ -117,7 +137,7 @@ func (g *defaultAssetHandler) Next() (*regources.AssetListResponse, error) {
 		})
 	}
 	result := &regources.AssetListResponse{}
-	err := g.base.PageFromLink(g.currentPageLinks.Next, result)
+	err := g.base.PageFromLinkContext(ctx, g.currentPageLinks.Next, result)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get next page", logan.F{
 			"link": g.currentPageLinks.Next,
@@ -129,6 +149,10 @@ func (g *defaultAssetHandler) Next() (*regources.AssetListResponse, error) {
 }
 
 func (g *defaultAssetHandler) Prev() (*regources.AssetListResponse, error) {
+	return g.PrevContext(context.Background())
+}
+
+func (g *defaultAssetHandler) PrevContext(ctx context.Context) (*regources.AssetListResponse, error) {
 	if g.currentPageLinks == nil {
 		return nil, errors.New("Empty links")
 	}
@@ -139,7 +163,7 @@ func (g *defaultAssetHandler) Prev() (*regources.AssetListResponse, error) {
 	}
 
 	result := &regources.AssetListResponse{}
-	err := g.base.PageFromLink(g.currentPageLinks.Prev, result)
+	err := g.base.PageFromLinkContext(ctx, g.currentPageLinks.Prev, result)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get previous page", logan.F{
 			"link": g.currentPageLinks.Prev,
@@ -151,6 +175,10 @@ func (g *defaultAssetHandler) Prev() (*regources.AssetListResponse, error) {
 }
 
 func (g *defaultAssetHandler) Self() (*regources.AssetListResponse, error) {
+	return g.SelfContext(context.Background())
+}
+
+func (g *defaultAssetHandler) SelfContext(ctx context.Context) (*regources.AssetListResponse, error) {
 	if g.currentPageLinks == nil {
 		return nil, errors.New("Empty links")
 	}
@@ -160,7 +188,7 @@ func (g *defaultAssetHandler) Self() (*regources.AssetListResponse, error) {
 		})
 	}
 	result := &regources.AssetListResponse{}
-	err := g.base.PageFromLink(g.currentPageLinks.Self, result)
+	err := g.base.PageFromLinkContext(ctx, g.currentPageLinks.Self, result)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get same page", logan.F{
 			"link": g.currentPageLinks.Self,
@@ -172,6 +200,10 @@ func (g *defaultAssetHandler) Self() (*regources.AssetListResponse, error) {
 }
 
 func (g *defaultAssetHandler) First() (*regources.AssetListResponse, error) {
+	return g.FirstContext(context.Background())
+}
+
+func (g *defaultAssetHandler) FirstContext(ctx context.Context) (*regources.AssetListResponse, error) {
 	if g.currentPageLinks == nil {
 		return nil, errors.New("Empty links")
 	}
@@ -181,7 +213,7 @@ func (g *defaultAssetHandler) First() (*regources.AssetListResponse, error) {
 		})
 	}
 	result := &regources.AssetListResponse{}
-	err := g.base.PageFromLink(g.currentPageLinks.First, result)
+	err := g.base.PageFromLinkContext(ctx, g.currentPageLinks.First, result)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get first page", logan.F{
 			"link": g.currentPageLinks.First,
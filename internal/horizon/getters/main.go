@@ -2,6 +2,7 @@ package getters
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/client"
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/query"
@@ -15,7 +16,9 @@ import (
 
 type Getter interface {
 	GetPage(endpoint string, params query.Params, result interface{}) error
+	GetPageContext(ctx context.Context, endpoint string, params query.Params, result interface{}) error
 	PageFromLink(link string, v interface{}) error
+	PageFromLinkContext(ctx context.Context, link string, v interface{}) error
 }
 
 type getter struct {
@@ -27,7 +30,11 @@ func New(client *client.Client) *getter {
 }
 
 func (g *getter) PageFromLink(link string, v interface{}) error {
-	resp, err := g.Get(link)
+	return g.PageFromLinkContext(context.Background(), link, v)
+}
+
+func (g *getter) PageFromLinkContext(ctx context.Context, link string, v interface{}) error {
+	resp, err := g.GetContext(ctx, link)
 	if err != nil {
 		return errors.Wrap(err, "failed to get page")
 	}
@@ -44,6 +51,10 @@ func (g *getter) PageFromLink(link string, v interface{}) error {
 }
 
 func (g *getter) GetPage(endpoint string, params query.Params, result interface{}) error {
+	return g.GetPageContext(context.Background(), endpoint, params, result)
+}
+
+func (g *getter) GetPageContext(ctx context.Context, endpoint string, params query.Params, result interface{}) error {
 	q := params.Prepare()
 	uri, err := g.Resolve().URI(endpoint, q)
 	if err != nil {
@@ -52,7 +63,7 @@ func (g *getter) GetPage(endpoint string, params query.Params, result interface{
 			"query":    params,
 		})
 	}
-	resp, err := g.Get(uri)
+	resp, err := g.GetContext(ctx, uri)
 	if err != nil {
 		return errors.Wrap(err, "failed to perform request")
 	}
@@ -5,6 +5,8 @@
 package getters
 
 import (
+	"context"
+
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/client"
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/page"
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/query"
@@ -15,9 +17,13 @@ import (
 
 type CreateWithdrawRequestPager interface {
 	Next() (*regources.ReviewableRequestListResponse, error)
+	NextContext(ctx context.Context) (*regources.ReviewableRequestListResponse, error)
 	Prev() (*regources.ReviewableRequestListResponse, error)
+	PrevContext(ctx context.Context) (*regources.ReviewableRequestListResponse, error)
 	Self() (*regources.ReviewableRequestListResponse, error)
+	SelfContext(ctx context.Context) (*regources.ReviewableRequestListResponse, error)
 	First() (*regources.ReviewableRequestListResponse, error)
+	FirstContext(ctx context.Context) (*regources.ReviewableRequestListResponse, error)
 }
 
 type CreateWithdrawRequestGetter interface {
@@ -31,7 +37,9 @@ type CreateWithdrawRequestGetter interface {
 	Page() page.Params
 
 	ByID(ID string) (*regources.ReviewableRequestResponse, error)
+	ByIDContext(ctx context.Context, ID string) (*regources.ReviewableRequestResponse, error)
 	List() (*regources.ReviewableRequestListResponse, error)
+	ListContext(ctx context.Context) (*regources.ReviewableRequestListResponse, error)
 }
 
 type CreateWithdrawRequestHandler interface {
@@ -85,8 +93,12 @@ func (g *defaultCreateWithdrawRequestHandler) Page() page.Params {
 }
 
 func (g *defaultCreateWithdrawRequestHandler) ByID(ID string) (*regources.ReviewableRequestResponse, error) {
+	return g.ByIDContext(context.Background(), ID)
+}
+
+func (g *defaultCreateWithdrawRequestHandler) ByIDContext(ctx context.Context, ID string) (*regources.ReviewableRequestResponse, error) {
 	result := &regources.ReviewableRequestResponse{}
-	err := g.base.GetPage(query.CreateWithdrawRequestByID(ID), g.params.Includes, result)
+	err := g.base.GetPageContext(ctx, query.CreateWithdrawRequestByID(ID), g.params.Includes, result)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get record by id", logan.F{
 			"id": ID,
@@ -96,8 +108,12 @@ func (g *defaultCreateWithdrawRequestHandler) ByID(ID string) (*regources.Review
 }
 
 func (g *defaultCreateWithdrawRequestHandler) List() (*regources.ReviewableRequestListResponse, error) {
+	return g.ListContext(context.Background())
+}
+
+func (g *defaultCreateWithdrawRequestHandler) ListContext(ctx context.Context) (*regources.ReviewableRequestListResponse, error) {
 	result := &regources.ReviewableRequestListResponse{}
-	err := g.base.GetPage(query.CreateWithdrawRequestList(), g.params, result)
+	err := g.base.GetPageContext(ctx, query.CreateWithdrawRequestList(), g.params, result)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get records list", logan.F{
 			"query_params": g.params,
@@ -108,6 +124,10 @@ func (g *defaultCreateWithdrawRequestHandler) List() (*regources.ReviewableReque
 }
 
 func (g *defaultCreateWithdrawRequestHandler) Next() (*regources.ReviewableRequestListResponse, error) {
+	return g.NextContext(context.Background())
+}
+
+func (g *defaultCreateWithdrawRequestHandler) NextContext(ctx context.Context) (*regources.ReviewableRequestListResponse, error) {
 	if g.currentPageLinks == nil {
 		return nil, errors.New("Empty links")
 	}
@@ -117,7 +137,7 @@ func (g *defaultCreateWithdrawRequestHandler) Next() (*regources.ReviewableReque
 		})
 	}
 	result := &regources.ReviewableRequestListResponse{}
-	err := g.base.PageFromLink(g.currentPageLinks.Next, result)
+	err := g.base.PageFromLinkContext(ctx, g.currentPageLinks.Next, result)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get next page", logan.F{
 			"link": g.currentPageLinks.Next,
@@ -129,6 +149,10 @@ func (g *defaultCreateWithdrawRequestHandler) Next() (*regources.ReviewableReque
 }
 
 func (g *defaultCreateWithdrawRequestHandler) Prev() (*regources.ReviewableRequestListResponse, error) {
+	return g.PrevContext(context.Background())
+}
+
+func (g *defaultCreateWithdrawRequestHandler) PrevContext(ctx context.Context) (*regources.ReviewableRequestListResponse, error) {
 	if g.currentPageLinks == nil {
 		return nil, errors.New("Empty links")
 	}
@@ -139,7 +163,7 @@ func (g *defaultCreateWithdrawRequestHandler) Prev() (*regources.ReviewableReque
 	}
 
 	result := &regources.ReviewableRequestListResponse{}
-	err := g.base.PageFromLink(g.currentPageLinks.Prev, result)
+	err := g.base.PageFromLinkContext(ctx, g.currentPageLinks.Prev, result)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get previous page", logan.F{
 			"link": g.currentPageLinks.Prev,
@@ -151,6 +175,10 @@ func (g *defaultCreateWithdrawRequestHandler) Prev() (*regources.ReviewableReque
 }
 
 func (g *defaultCreateWithdrawRequestHandler) Self() (*regources.ReviewableRequestListResponse, error) {
+	return g.SelfContext(context.Background())
+}
+
+func (g *defaultCreateWithdrawRequestHandler) SelfContext(ctx context.Context) (*regources.ReviewableRequestListResponse, error) {
 	if g.currentPageLinks == nil {
 		return nil, errors.New("Empty links")
 	}
@@ -160,7 +188,7 @@ func (g *defaultCreateWithdrawRequestHandler) Self() (*regources.ReviewableReque
 		})
 	}
 	result := &regources.ReviewableRequestListResponse{}
-	err := g.base.PageFromLink(g.currentPageLinks.Self, result)
+	err := g.base.PageFromLinkContext(ctx, g.currentPageLinks.Self, result)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get same page", logan.F{
 			"link": g.currentPageLinks.Self,
@@ -172,6 +200,10 @@ func (g *defaultCreateWithdrawRequestHandler) Self() (*regources.ReviewableReque
 }
 
 func (g *defaultCreateWithdrawRequestHandler) First() (*regources.ReviewableRequestListResponse, error) {
+	return g.FirstContext(context.Background())
+}
+
+func (g *defaultCreateWithdrawRequestHandler) FirstContext(ctx context.Context) (*regources.ReviewableRequestListResponse, error) {
 	if g.currentPageLinks == nil {
 		return nil, errors.New("Empty links")
 	}
@@ -181,7 +213,7 @@ func (g *defaultCreateWithdrawRequestHandler) First() (*regources.ReviewableRequ
 		})
 	}
 	result := &regources.ReviewableRequestListResponse{}
-	err := g.base.PageFromLink(g.currentPageLinks.First, result)
+	err := g.base.PageFromLinkContext(ctx, g.currentPageLinks.First, result)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get first page", logan.F{
 			"link": g.currentPageLinks.First,
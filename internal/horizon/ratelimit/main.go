@@ -0,0 +1,202 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	// rate is not lowered below this fraction of the configured one, however many times Horizon refuses requests
+	minRateDivisor = 16
+	// rate lowered by Horizon refusal is restored in this number of successful requests
+	recoverySteps = 20
+)
+
+// ErrDropped is returned if request would wait for its turn longer than allowed
+var ErrDropped = errors.New("request dropped by rate limiter")
+
+var (
+	// DefaultReads is a budget of requests reading Horizon
+	DefaultReads = Budget{Rate: 20, Burst: 100}
+	// DefaultSubmits is a budget of requests submitting transactions
+	DefaultSubmits = Budget{Rate: 5, Burst: 20}
+)
+
+// Budget configures token bucket
+type Budget struct {
+	// Rate is a number of requests per second allowed on average
+	Rate float64
+	// Burst is a number of requests allowed at once after idle period
+	Burst int
+	// MaxWait limits time request waits for its turn, zero means it is only limited by request context
+	MaxWait time.Duration
+}
+
+// Stats are counters of bucket since its creation
+type Stats struct {
+	// Rate is a current rate, it is below configured one while bucket backs off after Horizon refusals
+	Rate        float64 `json:"rate"`
+	Requests    uint64  `json:"requests"`
+	Waited      uint64  `json:"waited"`
+	WaitSeconds float64 `json:"wait_seconds"`
+	Dropped     uint64  `json:"dropped"`
+	Throttled   uint64  `json:"throttled"`
+}
+
+// Limiter limits requests to Horizon, reads and transaction submissions have separate budgets,
+// so bulk polling can't delay reviews
+type Limiter struct {
+	reads   *Bucket
+	submits *Bucket
+}
+
+// New creates limiter, it is meant to be shared by all clients of the same Horizon
+func New(reads, submits Budget) *Limiter {
+	return &Limiter{
+		reads:   newBucket(reads),
+		submits: newBucket(submits),
+	}
+}
+
+// For returns bucket limiting request: GET is a read, other methods submit
+func (l *Limiter) For(r *http.Request) *Bucket {
+	if r.Method == http.MethodGet {
+		return l.reads
+	}
+	return l.submits
+}
+
+// Stats returns counters of both budgets
+func (l *Limiter) Stats() map[string]Stats {
+	return map[string]Stats{
+		"reads":   l.reads.Stats(),
+		"submits": l.submits.Stats(),
+	}
+}
+
+// Bucket is a token bucket with rate adapting to refusals of Horizon
+type Bucket struct {
+	budget Budget
+
+	mu          sync.Mutex
+	rate        float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+	stats       Stats
+}
+
+func newBucket(budget Budget) *Bucket {
+	return &Bucket{
+		budget: budget,
+		rate:   budget.Rate,
+		tokens: float64(budget.Burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until request may be sent, ErrDropped is returned if it would take longer than MaxWait
+// or request context would expire before
+func (b *Bucket) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.refill(now)
+
+	b.tokens--
+	var wait time.Duration
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	// tokens are not refilled while paused, so pause adds up to the wait
+	if pause := b.pausedUntil.Sub(now); pause > 0 {
+		wait += pause
+	}
+
+	deadline, hasDeadline := ctx.Deadline()
+	if wait > 0 && (b.budget.MaxWait > 0 && wait > b.budget.MaxWait || hasDeadline && now.Add(wait).After(deadline)) {
+		b.tokens++
+		b.stats.Dropped++
+		b.mu.Unlock()
+		return errors.From(ErrDropped, logan.F{"wait": wait.String()})
+	}
+	b.stats.Requests++
+	if wait > 0 {
+		b.stats.Waited++
+		b.stats.WaitSeconds += wait.Seconds()
+	}
+	b.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.stats.Dropped++
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Throttled is called once Horizon refused request by its own rate limit. Requests are paused for retryAfter
+// and rate is halved, so bucket adapts to capacity Horizon actually provides.
+func (b *Bucket) Throttled(retryAfter time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.refill(now)
+
+	b.rate = math.Max(b.rate/2, b.budget.Rate/minRateDivisor)
+	if retryAfter <= 0 {
+		retryAfter = time.Duration(float64(time.Second) / b.rate)
+	}
+	if until := now.Add(retryAfter); until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+	// burst accumulated before refusal would hit Horizon again right after pause
+	b.tokens = math.Min(b.tokens, 0)
+	b.stats.Throttled++
+}
+
+// Succeeded is called once request passed Horizon rate limit, rate lowered before is restored gradually
+func (b *Bucket) Succeeded() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.rate >= b.budget.Rate {
+		return
+	}
+	b.refill(time.Now())
+	b.rate = math.Min(b.rate+b.budget.Rate/recoverySteps, b.budget.Rate)
+}
+
+// Stats returns counters of bucket
+func (b *Bucket) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	result := b.stats
+	result.Rate = b.rate
+	return result
+}
+
+// refill adds tokens earned since last refill, none are earned while paused
+func (b *Bucket) refill(now time.Time) {
+	from := b.last
+	if b.pausedUntil.After(from) {
+		from = b.pausedUntil
+	}
+	if now.After(from) {
+		b.tokens = math.Min(b.tokens+now.Sub(from).Seconds()*b.rate, float64(b.budget.Burst))
+	}
+	b.last = now
+}
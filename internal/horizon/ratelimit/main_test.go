@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+func TestBucket_Wait(t *testing.T) {
+	t.Run("burst", func(t *testing.T) {
+		b := newBucket(Budget{Rate: 1, Burst: 3})
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, b.Wait(context.Background()))
+		}
+		assert.True(t, time.Since(start) < 100*time.Millisecond)
+		assert.EqualValues(t, 0, b.Stats().Waited)
+	})
+
+	t.Run("waits for token", func(t *testing.T) {
+		b := newBucket(Budget{Rate: 10, Burst: 1})
+		assert.NoError(t, b.Wait(context.Background()))
+		start := time.Now()
+		assert.NoError(t, b.Wait(context.Background()))
+		assert.True(t, time.Since(start) >= 50*time.Millisecond)
+
+		stats := b.Stats()
+		assert.EqualValues(t, 2, stats.Requests)
+		assert.EqualValues(t, 1, stats.Waited)
+		assert.True(t, stats.WaitSeconds > 0)
+	})
+
+	t.Run("dropped by max wait", func(t *testing.T) {
+		b := newBucket(Budget{Rate: 1, Burst: 1, MaxWait: 100 * time.Millisecond})
+		assert.NoError(t, b.Wait(context.Background()))
+		assert.Equal(t, ErrDropped, errors.Cause(b.Wait(context.Background())))
+		assert.EqualValues(t, 1, b.Stats().Dropped)
+	})
+
+	t.Run("dropped by deadline", func(t *testing.T) {
+		b := newBucket(Budget{Rate: 1, Burst: 1})
+		assert.NoError(t, b.Wait(context.Background()))
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		assert.Equal(t, ErrDropped, errors.Cause(b.Wait(ctx)))
+		assert.True(t, time.Since(start) < 50*time.Millisecond)
+	})
+
+	t.Run("canceled returns token", func(t *testing.T) {
+		b := newBucket(Budget{Rate: 5, Burst: 1})
+		assert.NoError(t, b.Wait(context.Background()))
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+		assert.Equal(t, context.Canceled, b.Wait(ctx))
+		assert.EqualValues(t, 1, b.Stats().Dropped)
+
+		// canceled request does not delay the next one
+		start := time.Now()
+		assert.NoError(t, b.Wait(context.Background()))
+		assert.True(t, time.Since(start) < 250*time.Millisecond)
+	})
+}
+
+func TestBucket_Throttled(t *testing.T) {
+	b := newBucket(Budget{Rate: 100, Burst: 10})
+	b.Throttled(200 * time.Millisecond)
+
+	stats := b.Stats()
+	assert.EqualValues(t, 50, stats.Rate)
+	assert.EqualValues(t, 1, stats.Throttled)
+
+	// burst is dropped and requests are paused
+	start := time.Now()
+	assert.NoError(t, b.Wait(context.Background()))
+	assert.True(t, time.Since(start) >= 200*time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		b.Throttled(time.Millisecond)
+	}
+	assert.EqualValues(t, 100.0/minRateDivisor, b.Stats().Rate)
+
+	for i := 0; i < recoverySteps; i++ {
+		b.Succeeded()
+	}
+	assert.EqualValues(t, 100, b.Stats().Rate)
+}
+
+func TestLimiter_For(t *testing.T) {
+	l := New(Budget{Rate: 1, Burst: 1}, Budget{Rate: 1, Burst: 1})
+	get, _ := http.NewRequest(http.MethodGet, "http://localhost/v3/assets", nil)
+	post, _ := http.NewRequest(http.MethodPost, "http://localhost/v3/transactions", nil)
+
+	// reads exhausted don't delay submissions
+	assert.NoError(t, l.For(get).Wait(context.Background()))
+	start := time.Now()
+	assert.NoError(t, l.For(post).Wait(context.Background()))
+	assert.True(t, time.Since(start) < 100*time.Millisecond)
+
+	stats := l.Stats()
+	assert.EqualValues(t, 1, stats["reads"].Requests)
+	assert.EqualValues(t, 1, stats["submits"].Requests)
+}
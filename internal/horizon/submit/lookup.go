@@ -0,0 +1,150 @@
+package submit
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/tokend/erc20-withdraw-svc/internal/horizon/client"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	"gitlab.com/tokend/go/network"
+	"gitlab.com/tokend/go/xdr"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+// DefaultLookupPeriod is a delay between lookups of transaction with unknown outcome
+const DefaultLookupPeriod = 5 * time.Second
+
+// txDuplication is a result code of transaction with hash core has already applied
+const txDuplication = "tx_duplication"
+
+// ErrTxExpired is returned if transaction was not applied before its max time, so it never will be
+var ErrTxExpired = errors.New("transaction expired")
+
+// Outcome is a definitive result of transaction submission
+type Outcome int
+
+const (
+	// OutcomeApplied means core applied transaction
+	OutcomeApplied Outcome = iota + 1
+	// OutcomeFailed means core rejected transaction
+	OutcomeFailed
+	// OutcomeExpired means transaction expired without being applied
+	OutcomeExpired
+)
+
+// Lookup polls Horizon for transaction by hash until its outcome is known or ctx is done, zero outcome is returned
+// in the latter case along with the last lookup error.
+//
+// Transaction not found is submitted again: core rejects transaction with hash it has already applied,
+// so resubmission can't apply it twice, while transaction lost before reaching core gets applied.
+// Transaction still not found once Horizon ingested ledger closed after its max time is expired.
+func (s *submitter) Lookup(ctx context.Context, envelope string, waitIngest bool) (Outcome, *regources.TransactionResponse, error) {
+	var tx xdr.TransactionEnvelope
+	if err := xdr.SafeUnmarshalBase64(envelope, &tx); err != nil {
+		return 0, nil, errors.Wrap(err, "failed to unmarshal envelope")
+	}
+
+	ticker := time.NewTicker(s.period)
+	defer ticker.Stop()
+	for {
+		outcome, response, err := s.lookup(ctx, envelope, &tx, waitIngest)
+		if outcome != 0 {
+			return outcome, response, err
+		}
+		if err == nil {
+			err = errors.New("transaction is not found yet")
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, nil, err
+		case <-ticker.C:
+		}
+	}
+}
+
+// lookup makes a single attempt to find out transaction outcome, zero outcome means it is unknown yet
+func (s *submitter) lookup(
+	ctx context.Context, envelope string, tx *xdr.TransactionEnvelope, waitIngest bool,
+) (Outcome, *regources.TransactionResponse, error) {
+	state, err := s.state(ctx)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "failed to get horizon state")
+	}
+	hash, err := network.HashTransaction(&tx.Tx, state.NetworkPassphrase)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "failed to hash transaction")
+	}
+	fields := logan.F{"tx_hash": hex.EncodeToString(hash[:])}
+
+	response, err := s.transaction(ctx, hex.EncodeToString(hash[:]))
+	switch {
+	case err == nil:
+		return OutcomeApplied, response, nil
+	case !client.IsNotFound(err):
+		return 0, nil, errors.Wrap(err, "failed to get transaction", fields)
+	}
+
+	// Horizon time is a close time of the last ingested ledger, so no later ledger may contain transaction
+	maxTime := uint64(tx.Tx.TimeBounds.MaxTime)
+	if maxTime != 0 && uint64(state.CurrentTimeUnix) > maxTime {
+		return OutcomeExpired, nil, errors.From(ErrTxExpired, fields.Merge(logan.F{
+			"max_time":     maxTime,
+			"horizon_time": state.CurrentTimeUnix,
+		}))
+	}
+
+	response, err = s.submit(ctx, envelope, waitIngest)
+	if err == nil {
+		return OutcomeApplied, response, nil
+	}
+	failure, ok := err.(*TxFailure)
+	if !ok {
+		return 0, nil, errors.Wrap(err, "failed to submit transaction again", fields)
+	}
+	if failure.TransactionResultCode == txDuplication {
+		// applied, but not ingested yet
+		return 0, nil, nil
+	}
+
+	// transaction might be applied by previous submission between lookup and rejection
+	response, err = s.transaction(ctx, hex.EncodeToString(hash[:]))
+	switch {
+	case err == nil:
+		return OutcomeApplied, response, nil
+	case client.IsNotFound(err):
+		return OutcomeFailed, nil, failure
+	default:
+		return 0, nil, errors.Wrap(err, "failed to get transaction", fields)
+	}
+}
+
+func (s *submitter) transaction(ctx context.Context, hash string) (*regources.TransactionResponse, error) {
+	raw, err := s.GetContext(ctx, "/v3/transactions/"+url.PathEscape(hash))
+	if err != nil {
+		return nil, err
+	}
+
+	var response regources.TransactionResponse
+	if err := json.Unmarshal(raw, &response); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal transaction")
+	}
+	return &response, nil
+}
+
+func (s *submitter) state(ctx context.Context) (*regources.HorizonStateAttributes, error) {
+	raw, err := s.GetContext(ctx, "/v3/info")
+	if err != nil {
+		return nil, err
+	}
+
+	var response regources.HorizonStateResponse
+	if err := json.Unmarshal(raw, &response); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal horizon state")
+	}
+	return &response.Data.Attributes, nil
+}
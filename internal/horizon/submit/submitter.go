@@ -5,12 +5,14 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/client"
 	"gitlab.com/tokend/go/xdr"
 
 	regources "gitlab.com/tokend/regources/generated"
 
+	"gitlab.com/distributed_lab/logan/v3"
 	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
@@ -54,20 +56,45 @@ func (f *TxFailure) GetLoganFields() map[string]interface{} {
 }
 
 type Interface interface {
+	// Submit returns nil error only if transaction is applied, *TxFailure if core rejected it
+	// and ErrTxExpired if it expired without being applied. If Horizon response is ambiguous, transaction
+	// is looked up by hash until its outcome is known, error of ambiguous response is returned if ctx is done before.
 	Submit(ctx context.Context, envelope string, waitIngest bool) (*regources.TransactionResponse, error)
 }
 
 type submitter struct {
 	*client.Client
+	// period is a delay between lookups of transaction with unknown outcome
+	period time.Duration
 }
 
 func New(cl *client.Client) *submitter {
 	return &submitter{
 		Client: cl,
+		period: DefaultLookupPeriod,
 	}
 }
 
 func (s *submitter) Submit(ctx context.Context, envelope string, waitIngest bool) (*regources.TransactionResponse, error) {
+	response, err := s.submit(ctx, envelope, waitIngest)
+	if !isAmbiguous(err) {
+		return response, err
+	}
+
+	outcome, response, lookupErr := s.Lookup(ctx, envelope, waitIngest)
+	switch outcome {
+	case OutcomeApplied:
+		return response, nil
+	case OutcomeFailed, OutcomeExpired:
+		return nil, lookupErr
+	default:
+		return nil, errors.Wrap(err, "transaction outcome is unknown", logan.F{
+			"lookup_error": lookupErr.Error(),
+		})
+	}
+}
+
+func (s *submitter) submit(ctx context.Context, envelope string, waitIngest bool) (*regources.TransactionResponse, error) {
 	var buf bytes.Buffer
 	err := json.NewEncoder(&buf).Encode(&regources.SubmitTransactionBody{
 		Tx:            envelope,
@@ -84,9 +111,12 @@ func (s *submitter) Submit(ctx context.Context, envelope string, waitIngest bool
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to prepare request")
 	}
-	status, response, err := s.Do(r)
+	status, response, err := s.Do(r.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to submit transaction")
+	}
 
-	if isStatusCodeSuccessful(status) && err == nil {
+	if isStatusCodeSuccessful(status) {
 		var success regources.TransactionResponse
 		if err := json.Unmarshal(response, &success); err != nil {
 			return nil, errors.Wrap(err, "failed to unmarshal transaction response")
@@ -105,13 +135,20 @@ func (s *submitter) Submit(ctx context.Context, envelope string, waitIngest bool
 			return nil, errors.Wrap(err, "failed to unmarshal horizon response")
 		}
 		return nil, newTxFailure(failureResp)
-	case http.StatusInternalServerError: // internal error
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable: // internal error
 		return nil, ErrSubmitInternal
 	default:
 		return nil, ErrSubmitUnexpectedStatusCode
 	}
 }
 
+// isAmbiguous returns true if transaction may have been applied despite err: Horizon failed to wait for core
+// or connection broke after request was sent
+func isAmbiguous(err error) bool {
+	cause := errors.Cause(err)
+	return cause == ErrSubmitTimeout || cause == ErrSubmitInternal || client.IsTransient(err)
+}
+
 func isStatusCodeSuccessful(code int) bool {
 	return code >= 200 && code < 300
 }
@@ -0,0 +1,188 @@
+package submit
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tokend/erc20-withdraw-svc/internal/horizon/client"
+	"github.com/tokend/erc20-withdraw-svc/internal/horizon/fake"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	"gitlab.com/tokend/go/xdr"
+	"gitlab.com/tokend/go/xdrbuild"
+	"gitlab.com/tokend/keypair"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+const passphrase = "submit test network"
+
+type harness struct {
+	t       *testing.T
+	horizon *fake.Horizon
+	owner   keypair.Full
+	submit  *submitter
+}
+
+func newHarness(t *testing.T) *harness {
+	owner, err := keypair.Random()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &harness{
+		t:       t,
+		horizon: fake.New(passphrase),
+		owner:   owner,
+	}
+	h.horizon.AddAsset(regources.Asset{
+		Key: regources.Key{ID: "TKN"},
+		Relationships: regources.AssetRelationships{
+			Owner: &regources.Relation{Data: &regources.Key{ID: owner.Address(), Type: regources.ACCOUNTS}},
+		},
+	})
+	h.submit = New(client.New(http.DefaultClient, h.horizon.URL()))
+	h.submit.period = 50 * time.Millisecond
+	return h
+}
+
+// request creates withdraw request approved by removing the only pending task
+func (h *harness) request() string {
+	return h.horizon.CreateWithdraw(fake.Withdraw{Asset: "TKN", Tasks: 1})
+}
+
+// approve returns envelope approving withdraw request, valid until maxTime
+func (h *harness) approve(id, details string, maxTime time.Time) string {
+	request := h.horizon.Request(id)
+	requestID, _ := strconv.ParseUint(id, 10, 64)
+
+	envelope, err := xdrbuild.NewBuilder(passphrase, 0).
+		Transaction(h.owner).
+		TimeBounds(0, maxTime.Unix()).
+		Op(xdrbuild.ReviewRequest{
+			ID:      requestID,
+			Hash:    &request.Hash,
+			Action:  xdr.ReviewRequestOpActionApprove,
+			Details: xdrbuild.WithdrawalDetails{ExternalDetails: details},
+			ReviewDetails: xdrbuild.ReviewDetails{
+				TasksToRemove:   1,
+				ExternalDetails: details,
+			},
+		}).
+		Sign(h.owner).
+		Marshal()
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	return envelope
+}
+
+// timeouts makes n next submissions time out without being applied
+func (h *harness) timeouts(n int) {
+	faults := make([]fake.Fault, n)
+	for i := range faults {
+		faults[i] = fake.Fault{Status: http.StatusGatewayTimeout}
+	}
+	h.horizon.FailSubmits(faults...)
+}
+
+func TestSubmitter_Submit(t *testing.T) {
+	t.Run("applied", func(t *testing.T) {
+		h := newHarness(t)
+		defer h.horizon.Close()
+		id := h.request()
+
+		_, err := h.submit.Submit(context.Background(), h.approve(id, "{}", time.Now().Add(time.Hour)), true)
+		assert.NoError(t, err)
+		assert.Equal(t, fake.StateApproved, h.horizon.Request(id).State)
+	})
+
+	t.Run("applied despite timeout", func(t *testing.T) {
+		h := newHarness(t)
+		defer h.horizon.Close()
+		id := h.request()
+		h.horizon.FailSubmits(fake.Fault{Status: http.StatusGatewayTimeout, Apply: true})
+
+		response, err := h.submit.Submit(context.Background(), h.approve(id, "{}", time.Now().Add(time.Hour)), true)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.Data.Attributes.Hash)
+		assert.Equal(t, fake.StateApproved, h.horizon.Request(id).State)
+		assert.Len(t, h.horizon.Request(id).ExternalDetails, 1)
+		assert.Equal(t, 1, h.horizon.Applied())
+	})
+
+	t.Run("lost on internal error", func(t *testing.T) {
+		h := newHarness(t)
+		defer h.horizon.Close()
+		id := h.request()
+		h.horizon.FailSubmits(fake.Fault{Status: http.StatusInternalServerError})
+
+		_, err := h.submit.Submit(context.Background(), h.approve(id, "{}", time.Now().Add(time.Hour)), true)
+		assert.NoError(t, err)
+		assert.Equal(t, fake.StateApproved, h.horizon.Request(id).State)
+		assert.Equal(t, 1, h.horizon.Applied())
+	})
+
+	t.Run("applied but not ingested", func(t *testing.T) {
+		h := newHarness(t)
+		defer h.horizon.Close()
+		id := h.request()
+		envelope := h.approve(id, "{}", time.Now().Add(time.Hour))
+		h.horizon.FailSubmits(fake.Fault{Status: http.StatusGatewayTimeout, Apply: true})
+		_, err := h.submit.submit(context.Background(), envelope, true)
+		assert.Equal(t, ErrSubmitTimeout, err)
+
+		// core refuses to apply the same transaction twice
+		_, err = h.submit.submit(context.Background(), envelope, true)
+		failure, ok := err.(*TxFailure)
+		if assert.True(t, ok) {
+			assert.Equal(t, txDuplication, failure.TransactionResultCode)
+		}
+		outcome, _, err := h.submit.Lookup(context.Background(), envelope, true)
+		assert.Equal(t, OutcomeApplied, outcome)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, h.horizon.Applied())
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		h := newHarness(t)
+		defer h.horizon.Close()
+		id := h.request()
+		envelope := h.approve(id, "{}", time.Now().Add(time.Hour))
+		// request is approved by another transaction, so resubmitted one is rejected
+		_, err := h.submit.Submit(context.Background(), h.approve(id, `{"other":true}`, time.Now().Add(time.Hour)), true)
+		assert.NoError(t, err)
+		h.timeouts(1)
+
+		_, err = h.submit.Submit(context.Background(), envelope, true)
+		failure, ok := err.(*TxFailure)
+		if assert.True(t, ok) {
+			assert.Equal(t, "tx_failed", failure.TransactionResultCode)
+		}
+		assert.Equal(t, 1, h.horizon.Applied())
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		h := newHarness(t)
+		defer h.horizon.Close()
+		id := h.request()
+		h.timeouts(100)
+
+		_, err := h.submit.Submit(context.Background(), h.approve(id, "{}", time.Now().Add(time.Second)), true)
+		assert.Equal(t, ErrTxExpired, errors.Cause(err))
+		assert.Equal(t, fake.StatePending, h.horizon.Request(id).State)
+	})
+
+	t.Run("unknown once ctx is done", func(t *testing.T) {
+		h := newHarness(t)
+		defer h.horizon.Close()
+		id := h.request()
+		h.timeouts(100)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		_, err := h.submit.Submit(ctx, h.approve(id, "{}", time.Now().Add(time.Hour)), true)
+		assert.Equal(t, ErrSubmitTimeout, errors.Cause(err))
+	})
+}
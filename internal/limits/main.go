@@ -0,0 +1,162 @@
+package limits
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tokend/erc20-withdraw-svc/internal/config"
+	"github.com/tokend/erc20-withdraw-svc/internal/storage"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	"gitlab.com/tokend/go/amount"
+)
+
+// Withdrawal is withdrawal checked against and accounted in limits
+type Withdrawal struct {
+	RequestID   string `json:"request_id"`
+	Asset       string `json:"asset"`
+	Destination string `json:"destination"`
+	Requestor   string `json:"requestor"`
+	Amount      uint64 `json:"amount"`
+}
+
+type record struct {
+	Withdrawal
+	Time time.Time `json:"time"`
+}
+
+// Violation describes limit withdrawal would exceed
+type Violation struct {
+	Limit string
+	Value uint64
+	// Cap is set if withdrawal exceeds per withdrawal cap and so would never fit into limits
+	Cap bool
+}
+
+func (v Violation) String() string {
+	return "exceeds " + v.Limit + " limit of " + amount.StringU(v.Value)
+}
+
+// Tracker accounts sent withdrawals in rolling windows, persisted across restarts.
+// Withdrawals which are about to be sent are reserved in memory only, so they are not accounted twice
+// once collected again after restart.
+type Tracker struct {
+	file      *storage.File
+	retention time.Duration
+
+	mu       sync.Mutex
+	records  []record
+	reserved map[string]record
+}
+
+// New creates tracker persisted in file, records older than retention are dropped
+func New(file *storage.File, retention time.Duration) (*Tracker, error) {
+	var records []record
+	if err := file.Load(&records); err != nil {
+		return nil, errors.Wrap(err, "failed to load withdrawal records")
+	}
+
+	return &Tracker{
+		file:      file,
+		retention: retention,
+		records:   records,
+		reserved:  make(map[string]record),
+	}, nil
+}
+
+// Check returns the first limit withdrawal would exceed, nil if withdrawal fits into all of them
+func (t *Tracker) Check(limits config.AssetLimits, withdrawal Withdrawal) *Violation {
+	if exceeds(0, withdrawal.Amount, uint64(limits.MaxAmount)) {
+		return &Violation{Limit: "per withdrawal", Value: uint64(limits.MaxAmount), Cap: true}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var total, perDestination, perRequestor uint64
+	since := time.Now().UTC().Add(-limits.Window)
+	records := append(make([]record, 0, len(t.records)+len(t.reserved)), t.records...)
+	for _, r := range t.reserved {
+		records = append(records, r)
+	}
+	for _, r := range records {
+		if r.Asset != withdrawal.Asset || r.Time.Before(since) || r.RequestID == withdrawal.RequestID {
+			continue
+		}
+		total = add(total, r.Amount)
+		if strings.EqualFold(r.Destination, withdrawal.Destination) {
+			perDestination = add(perDestination, r.Amount)
+		}
+		if r.Requestor == withdrawal.Requestor {
+			perRequestor = add(perRequestor, r.Amount)
+		}
+	}
+
+	switch {
+	case exceeds(total, withdrawal.Amount, uint64(limits.Total)):
+		return &Violation{Limit: "total", Value: uint64(limits.Total)}
+	case exceeds(perDestination, withdrawal.Amount, uint64(limits.PerDestination)):
+		return &Violation{Limit: "per destination", Value: uint64(limits.PerDestination)}
+	case exceeds(perRequestor, withdrawal.Amount, uint64(limits.PerRequestor)):
+		return &Violation{Limit: "per requestor", Value: uint64(limits.PerRequestor)}
+	}
+
+	return nil
+}
+
+// Reserve accounts withdrawal which is going to be sent, until it is either recorded or released
+func (t *Tracker) Reserve(withdrawal Withdrawal) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reserved[withdrawal.RequestID] = record{Withdrawal: withdrawal, Time: time.Now().UTC()}
+}
+
+// Release drops reservation of withdrawal which was not sent
+func (t *Tracker) Release(requestID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.reserved, requestID)
+}
+
+// Record accounts sent withdrawal, replacing its reservation if any. Recording the same request twice has no effect.
+func (t *Tracker) Record(withdrawal Withdrawal) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now().UTC()
+	records := make([]record, 0, len(t.records)+1)
+	for _, r := range t.records {
+		if r.RequestID == withdrawal.RequestID {
+			delete(t.reserved, withdrawal.RequestID)
+			return nil
+		}
+		if now.Sub(r.Time) > t.retention {
+			continue
+		}
+		records = append(records, r)
+	}
+	records = append(records, record{Withdrawal: withdrawal, Time: now})
+
+	if err := t.file.Save(records); err != nil {
+		return errors.Wrap(err, "failed to persist withdrawal record")
+	}
+	t.records = records
+	delete(t.reserved, withdrawal.RequestID)
+
+	return nil
+}
+
+// exceeds reports whether used+amount is above limit without overflowing, zero limit is no limit
+func exceeds(used, amount, limit uint64) bool {
+	if limit == 0 {
+		return false
+	}
+	return amount > limit || used > limit-amount
+}
+
+func add(a, b uint64) uint64 {
+	if a+b < a {
+		return ^uint64(0)
+	}
+	return a + b
+}
@@ -0,0 +1,124 @@
+package limits
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tokend/erc20-withdraw-svc/internal/config"
+	"github.com/tokend/erc20-withdraw-svc/internal/storage"
+)
+
+func TestTracker_Check(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limits")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	limits := config.AssetLimits{MaxAmount: 50, Total: 100, PerDestination: 60, PerRequestor: 70, Window: time.Hour}
+	tracker, err := New(storage.NewFile(dir, "limits.json"), time.Hour)
+	assert.NoError(t, err)
+
+	// withdrawal above cap never fits, even with no withdrawals sent yet
+	violation := tracker.Check(limits, Withdrawal{RequestID: "1", Asset: "TKN", Destination: "0xa", Requestor: "GA", Amount: 51})
+	if assert.NotNil(t, violation) {
+		assert.True(t, violation.Cap)
+		assert.Equal(t, "per withdrawal", violation.Limit)
+	}
+	assert.Nil(t, tracker.Check(limits, Withdrawal{RequestID: "1", Asset: "TKN", Destination: "0xa", Requestor: "GA", Amount: 50}))
+
+	assert.NoError(t, tracker.Record(Withdrawal{RequestID: "1", Asset: "TKN", Destination: "0xA", Requestor: "GA", Amount: 50}))
+	// recording the same request twice has no effect
+	assert.NoError(t, tracker.Record(Withdrawal{RequestID: "1", Asset: "TKN", Destination: "0xA", Requestor: "GA", Amount: 50}))
+
+	// destination is compared case insensitively
+	violation = tracker.Check(limits, Withdrawal{RequestID: "2", Asset: "TKN", Destination: "0xa", Requestor: "GB", Amount: 11})
+	if assert.NotNil(t, violation) {
+		assert.False(t, violation.Cap)
+		assert.Equal(t, "per destination", violation.Limit)
+	}
+	violation = tracker.Check(limits, Withdrawal{RequestID: "2", Asset: "TKN", Destination: "0xb", Requestor: "GA", Amount: 21})
+	if assert.NotNil(t, violation) {
+		assert.Equal(t, "per requestor", violation.Limit)
+	}
+	assert.Nil(t, tracker.Check(limits, Withdrawal{RequestID: "2", Asset: "TKN", Destination: "0xb", Requestor: "GB", Amount: 50}))
+	// other assets are accounted separately
+	assert.Nil(t, tracker.Check(limits, Withdrawal{RequestID: "2", Asset: "OTH", Destination: "0xa", Requestor: "GA", Amount: 50}))
+	// request is not accounted against itself when checked again
+	assert.Nil(t, tracker.Check(limits, Withdrawal{RequestID: "1", Asset: "TKN", Destination: "0xa", Requestor: "GA", Amount: 50}))
+
+	assert.NoError(t, tracker.Record(Withdrawal{RequestID: "2", Asset: "TKN", Destination: "0xb", Requestor: "GB", Amount: 40}))
+	violation = tracker.Check(limits, Withdrawal{RequestID: "3", Asset: "TKN", Destination: "0xc", Requestor: "GC", Amount: 11})
+	if assert.NotNil(t, violation) {
+		assert.Equal(t, "total", violation.Limit)
+		assert.EqualValues(t, 100, violation.Value)
+	}
+
+	// zero limits are no limits
+	assert.Nil(t, tracker.Check(config.AssetLimits{Window: time.Hour}, Withdrawal{RequestID: "3", Asset: "TKN", Amount: ^uint64(0)}))
+}
+
+func TestTracker_Window(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limits")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Now().UTC()
+	file := storage.NewFile(dir, "limits.json")
+	assert.NoError(t, file.Save([]record{
+		{Withdrawal: Withdrawal{RequestID: "1", Asset: "TKN", Amount: 60}, Time: now.Add(-3 * time.Hour)},
+		{Withdrawal: Withdrawal{RequestID: "2", Asset: "TKN", Amount: 30}, Time: now.Add(-30 * time.Minute)},
+	}))
+	tracker, err := New(file, 2*time.Hour)
+	assert.NoError(t, err)
+
+	// withdrawals sent before the window are not accounted
+	limits := config.AssetLimits{Total: 100, Window: time.Hour}
+	assert.Nil(t, tracker.Check(limits, Withdrawal{RequestID: "3", Asset: "TKN", Amount: 70}))
+	assert.NotNil(t, tracker.Check(limits, Withdrawal{RequestID: "3", Asset: "TKN", Amount: 71}))
+	limits.Window = 4 * time.Hour
+	assert.NotNil(t, tracker.Check(limits, Withdrawal{RequestID: "3", Asset: "TKN", Amount: 11}))
+
+	// records older than retention are dropped once next withdrawal is recorded
+	assert.NoError(t, tracker.Record(Withdrawal{RequestID: "3", Asset: "TKN", Amount: 10}))
+	var records []record
+	assert.NoError(t, file.Load(&records))
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "2", records[0].RequestID)
+		assert.Equal(t, "3", records[1].RequestID)
+	}
+}
+
+func TestTracker_Reserve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limits")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	limits := config.AssetLimits{Total: 100, Window: time.Hour}
+	withdrawal := func(id string, amount uint64) Withdrawal {
+		return Withdrawal{RequestID: id, Asset: "TKN", Destination: "0xdest", Requestor: "GREQ", Amount: amount}
+	}
+	tracker, err := New(storage.NewFile(dir, "limits.json"), time.Hour)
+	assert.NoError(t, err)
+
+	tracker.Reserve(withdrawal("1", 60))
+	tracker.Reserve(withdrawal("2", 30))
+	// queued withdrawals can't exceed limits together
+	assert.NotNil(t, tracker.Check(limits, withdrawal("3", 20)))
+	// failed withdrawal does not hold the limit
+	tracker.Release("2")
+	assert.Nil(t, tracker.Check(limits, withdrawal("3", 20)))
+
+	assert.NoError(t, tracker.Record(withdrawal("1", 60)))
+	assert.Nil(t, tracker.Check(limits, withdrawal("3", 40)))
+	assert.NotNil(t, tracker.Check(limits, withdrawal("3", 41)))
+
+	// reservations are not persisted, so withdrawal collected again after restart is not accounted twice
+	tracker.Reserve(withdrawal("4", 40))
+	restarted, err := New(storage.NewFile(dir, "limits.json"), time.Hour)
+	assert.NoError(t, err)
+	restarted.Reserve(withdrawal("4", 40))
+	assert.Nil(t, restarted.Check(limits, withdrawal("5", 0)))
+	assert.NotNil(t, restarted.Check(limits, withdrawal("5", 1)))
+}
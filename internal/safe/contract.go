@@ -0,0 +1,127 @@
+package safe
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// safeABI is a part of Safe ABI used by the service, signatures are the same since Safe 1.0
+const safeABI = `[
+{"constant":true,"inputs":[],"name":"nonce","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[],"name":"getThreshold","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"isOwner","outputs":[{"name":"","type":"bool"}],"payable":false,"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"_nonce","type":"uint256"}],"name":"getTransactionHash","outputs":[{"name":"","type":"bytes32"}],"payable":false,"stateMutability":"view","type":"function"},
+{"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"signatures","type":"bytes"}],"name":"execTransaction","outputs":[{"name":"success","type":"bool"}],"payable":true,"stateMutability":"payable","type":"function"},
+{"anonymous":false,"inputs":[{"indexed":false,"name":"txHash","type":"bytes32"},{"indexed":false,"name":"payment","type":"uint256"}],"name":"ExecutionSuccess","type":"event"}
+]`
+
+// Tx is a Safe transaction calling `To` with `Data`, without value, refunds and delegate calls
+type Tx struct {
+	To    common.Address
+	Data  []byte
+	Nonce uint64
+}
+
+// Contract is a bound Safe
+type Contract struct {
+	address  common.Address
+	abi      abi.ABI
+	contract *bind.BoundContract
+}
+
+// NewContract binds Safe at address
+func NewContract(address common.Address, backend bind.ContractBackend) (*Contract, error) {
+	parsed, err := abi.JSON(strings.NewReader(safeABI))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse safe ABI")
+	}
+
+	return &Contract{
+		address:  address,
+		abi:      parsed,
+		contract: bind.NewBoundContract(address, parsed, backend, backend, backend),
+	}, nil
+}
+
+// Address returns address of the Safe
+func (c *Contract) Address() common.Address {
+	return c.address
+}
+
+// Nonce returns nonce of the next Safe transaction
+func (c *Contract) Nonce(ctx context.Context) (uint64, error) {
+	nonce := new(*big.Int)
+	if err := c.contract.Call(&bind.CallOpts{Context: ctx}, nonce, "nonce"); err != nil {
+		return 0, errors.Wrap(err, "failed to get safe nonce")
+	}
+	return (*nonce).Uint64(), nil
+}
+
+// Threshold returns number of owners required to confirm Safe transaction
+func (c *Contract) Threshold(ctx context.Context) (uint64, error) {
+	threshold := new(*big.Int)
+	if err := c.contract.Call(&bind.CallOpts{Context: ctx}, threshold, "getThreshold"); err != nil {
+		return 0, errors.Wrap(err, "failed to get safe threshold")
+	}
+	return (*threshold).Uint64(), nil
+}
+
+// IsOwner returns true if address is an owner of the Safe
+func (c *Contract) IsOwner(ctx context.Context, address common.Address) (bool, error) {
+	isOwner := new(bool)
+	if err := c.contract.Call(&bind.CallOpts{Context: ctx}, isOwner, "isOwner", address); err != nil {
+		return false, errors.Wrap(err, "failed to check safe owner")
+	}
+	return *isOwner, nil
+}
+
+// TransactionHash returns hash of Safe transaction owners sign, as computed by the Safe itself
+func (c *Contract) TransactionHash(ctx context.Context, tx Tx) (common.Hash, error) {
+	hash := new([32]byte)
+	err := c.contract.Call(&bind.CallOpts{Context: ctx}, hash, "getTransactionHash",
+		tx.To, big.NewInt(0), tx.Data, uint8(0), big.NewInt(0), big.NewInt(0), big.NewInt(0),
+		common.Address{}, common.Address{}, new(big.Int).SetUint64(tx.Nonce),
+	)
+	if err != nil {
+		return common.Hash{}, errors.Wrap(err, "failed to get safe transaction hash")
+	}
+	return common.Hash(*hash), nil
+}
+
+// Exec sends transaction executing Safe transaction confirmed by signatures
+func (c *Contract) Exec(opts *bind.TransactOpts, tx Tx, signatures []byte) (*types.Transaction, error) {
+	return c.contract.Transact(opts, "execTransaction",
+		tx.To, big.NewInt(0), tx.Data, uint8(0), big.NewInt(0), big.NewInt(0), big.NewInt(0),
+		common.Address{}, common.Address{}, signatures,
+	)
+}
+
+// Executed returns true if logs contain successful execution of Safe transaction with given hash
+func (c *Contract) Executed(logs []*types.Log, safeTxHash common.Hash) bool {
+	id := c.abi.Events["ExecutionSuccess"].Id()
+	for _, log := range logs {
+		if log.Removed || log.Address != c.address || len(log.Topics) == 0 || log.Topics[0] != id {
+			continue
+		}
+
+		var event struct {
+			TxHash  [32]byte
+			Payment *big.Int
+		}
+		if err := c.contract.UnpackLog(&event, "ExecutionSuccess", *log); err != nil {
+			continue
+		}
+		if common.Hash(event.TxHash) == safeTxHash {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,241 @@
+package safe
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tokend/erc20-withdraw-svc/internal/storage"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+var (
+	ErrNotFound         = errors.New("safe transaction not found")
+	ErrAlreadyConfirmed = errors.New("owner has already confirmed safe transaction")
+	ErrNonceTaken       = errors.New("safe nonce is taken by another proposal")
+)
+
+// Proposal is a Safe transaction delivering withdrawal, waiting for confirmations of Safe owners
+type Proposal struct {
+	ID    string `json:"id"`
+	Asset string `json:"asset"`
+	// Address is a destination of withdrawal
+	Address string `json:"address"`
+	// Amount is an amount of tokens being sent, in token units
+	Amount string `json:"amount"`
+
+	Safe       string    `json:"safe"`
+	To         string    `json:"to"`
+	Data       string    `json:"data"`
+	Nonce      uint64    `json:"nonce"`
+	SafeTxHash string    `json:"safe_tx_hash"`
+	ProposedAt time.Time `json:"proposed_at"`
+	// Signatures are confirmations by owner address, they are verified against Safe owners before execution
+	Signatures map[string]string `json:"signatures"`
+	// Published is set once Safe transaction hash is written to request external details
+	Published bool `json:"published"`
+}
+
+// Store keeps Safe transaction proposals persisted across restarts
+type Store struct {
+	file      *storage.File
+	mu        sync.RWMutex
+	proposals map[string]Proposal
+}
+
+// New creates store persisted in file, loading previously created proposals
+func New(file *storage.File) (*Store, error) {
+	proposals := make(map[string]Proposal)
+	if err := file.Load(&proposals); err != nil {
+		return nil, errors.Wrap(err, "failed to load safe transactions")
+	}
+
+	return &Store{
+		file:      file,
+		proposals: proposals,
+	}, nil
+}
+
+// Propose stores proposal for the request, replacing stale proposal of the same request if any.
+// ErrNonceTaken is returned if proposal of another request has the same nonce.
+func (s *Store) Propose(proposal Proposal) (*Proposal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, other := range s.proposals {
+		if id != proposal.ID && strings.EqualFold(other.Safe, proposal.Safe) && other.Nonce == proposal.Nonce {
+			return nil, errors.From(ErrNonceTaken, logan.F{"nonce": proposal.Nonce, "request_id": id})
+		}
+	}
+
+	previous, existed := s.proposals[proposal.ID]
+	s.proposals[proposal.ID] = proposal
+	if err := s.file.Save(s.proposals); err != nil {
+		if existed {
+			s.proposals[proposal.ID] = previous
+		} else {
+			delete(s.proposals, proposal.ID)
+		}
+		return nil, errors.Wrap(err, "failed to persist safe transaction")
+	}
+
+	return &proposal, nil
+}
+
+// Confirm adds signature of owner to the proposal with given Safe transaction hash,
+// hash is required so confirmation of replaced proposal is not applied to the new one
+func (s *Store) Confirm(id, safeTxHash, owner, signature string) (*Proposal, error) {
+	var result Proposal
+	err := s.update(id, func(proposal *Proposal) error {
+		if !strings.EqualFold(proposal.SafeTxHash, safeTxHash) {
+			return ErrNotFound
+		}
+		owner = strings.ToLower(owner)
+		if _, ok := proposal.Signatures[owner]; ok {
+			return ErrAlreadyConfirmed
+		}
+		signatures := make(map[string]string, len(proposal.Signatures)+1)
+		for k, v := range proposal.Signatures {
+			signatures[k] = v
+		}
+		signatures[owner] = signature
+		proposal.Signatures = signatures
+		result = *proposal
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Published marks Safe transaction hash of the proposal as written to request external details
+func (s *Store) Published(id string) error {
+	return s.update(id, func(proposal *Proposal) error {
+		proposal.Published = true
+		return nil
+	})
+}
+
+// Remove removes proposal, its nonce is left free until a new proposal or one of later proposals takes it
+func (s *Store) Remove(id string) (*Proposal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proposal, ok := s.proposals[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	delete(s.proposals, id)
+	if err := s.file.Save(s.proposals); err != nil {
+		s.proposals[id] = proposal
+		return nil, errors.Wrap(err, "failed to persist safe transaction")
+	}
+
+	return &proposal, nil
+}
+
+// NextNonce returns the lowest Safe nonce not below on-chain one which is not taken by pending proposals,
+// so nonce left by removed proposal is filled and does not block Safe transactions following it
+func (s *Store) NextNonce(safe string, onChain uint64) uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	taken := make(map[uint64]bool)
+	for _, proposal := range s.proposals {
+		if strings.EqualFold(proposal.Safe, safe) && proposal.Nonce >= onChain {
+			taken[proposal.Nonce] = true
+		}
+	}
+
+	next := onChain
+	for taken[next] {
+		next++
+	}
+	return next
+}
+
+// Get returns proposal by request id
+func (s *Store) Get(id string) (*Proposal, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	proposal, ok := s.proposals[id]
+	if !ok {
+		return nil, false
+	}
+
+	return &proposal, true
+}
+
+// Prune removes proposals of requests which left pending state, nonces of unexecuted ones are taken by later proposals
+func (s *Store) Prune(ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := make(map[string]Proposal, len(ids))
+	for _, id := range ids {
+		if value, ok := s.proposals[id]; ok {
+			removed[id] = value
+			delete(s.proposals, id)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	if err := s.file.Save(s.proposals); err != nil {
+		for id, value := range removed {
+			s.proposals[id] = value
+		}
+		return errors.Wrap(err, "failed to persist safe transactions")
+	}
+
+	return nil
+}
+
+// List returns all known proposals ordered by Safe and nonce
+func (s *Store) List() []Proposal {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Proposal, 0, len(s.proposals))
+	for _, proposal := range s.proposals {
+		result = append(result, proposal)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Safe != result[j].Safe {
+			return result[i].Safe < result[j].Safe
+		}
+		return result[i].Nonce < result[j].Nonce
+	})
+
+	return result
+}
+
+func (s *Store) update(id string, fn func(proposal *Proposal) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proposal, ok := s.proposals[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	previous := proposal
+	if err := fn(&proposal); err != nil {
+		return err
+	}
+	s.proposals[id] = proposal
+
+	if err := s.file.Save(s.proposals); err != nil {
+		s.proposals[id] = previous
+		return errors.Wrap(err, "failed to persist safe transaction")
+	}
+
+	return nil
+}
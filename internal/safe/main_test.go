@@ -0,0 +1,108 @@
+package safe
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/tokend/erc20-withdraw-svc/internal/storage"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+func TestRecover(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	hash := crypto.Keccak256Hash([]byte("safe tx"))
+
+	signature, err := Sign(hash, key)
+	assert.NoError(t, err)
+	recovered, err := Recover(hash, signature)
+	assert.NoError(t, err)
+	assert.Equal(t, owner, recovered)
+
+	// eth_sign signature of the same hash, as produced by wallets
+	prefixed := crypto.Keccak256([]byte("\x19Ethereum Signed Message:\n32"), hash.Bytes())
+	ethSign, err := crypto.Sign(prefixed, key)
+	assert.NoError(t, err)
+	ethSign[recoveryIDOffset] += 31
+	recovered, err = Recover(hash, ethSign)
+	assert.NoError(t, err)
+	assert.Equal(t, owner, recovered)
+
+	recovered, err = Recover(crypto.Keccak256Hash([]byte("other tx")), signature)
+	assert.NoError(t, err)
+	assert.NotEqual(t, owner, recovered)
+
+	_, err = Recover(hash, signature[:64])
+	assert.Equal(t, ErrInvalidSignature, err)
+}
+
+func TestPack(t *testing.T) {
+	low := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	high := common.HexToAddress("0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee")
+	lowSig := bytes.Repeat([]byte{1}, signatureLength)
+	highSig := bytes.Repeat([]byte{2}, signatureLength)
+
+	packed := Pack(map[common.Address][]byte{high: highSig, low: lowSig})
+	assert.Equal(t, append(append([]byte{}, lowSig...), highSig...), packed)
+}
+
+func TestNextNonce(t *testing.T) {
+	dir, err := ioutil.TempDir("", "safe")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := New(storage.NewFile(dir, "safe.json"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, store.NextNonce("0xSAFE", 5))
+
+	_, err = store.Propose(Proposal{ID: "1", Safe: "0xsafe", Nonce: 5})
+	assert.NoError(t, err)
+	_, err = store.Propose(Proposal{ID: "2", Safe: "0xother", Nonce: 9})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 6, store.NextNonce("0xSAFE", 5))
+	// executed proposals are behind on-chain nonce
+	assert.EqualValues(t, 7, store.NextNonce("0xSAFE", 7))
+
+	_, err = store.Remove("1")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, store.NextNonce("0xSAFE", 5))
+}
+
+func TestDiscardInTheMiddle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "safe")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := New(storage.NewFile(dir, "safe.json"))
+	assert.NoError(t, err)
+	for _, id := range []string{"1", "2", "3"} {
+		_, err = store.Propose(Proposal{ID: id, Safe: "0xsafe", Nonce: store.NextNonce("0xsafe", 5)})
+		assert.NoError(t, err)
+	}
+	assert.EqualValues(t, 8, store.NextNonce("0xsafe", 5))
+
+	_, err = store.Remove("2")
+	assert.NoError(t, err)
+	// freed nonce is filled instead of the one following proposals
+	assert.EqualValues(t, 6, store.NextNonce("0xsafe", 5))
+	_, err = store.Propose(Proposal{ID: "3", Safe: "0xsafe", Nonce: 6})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 7, store.NextNonce("0xsafe", 5))
+
+	_, err = store.Propose(Proposal{ID: "4", Safe: "0xSAFE", Nonce: 6})
+	assert.Equal(t, ErrNonceTaken, errors.Cause(err))
+	_, ok := store.Get("4")
+	assert.False(t, ok)
+
+	proposals := store.List()
+	if assert.Len(t, proposals, 2) {
+		assert.EqualValues(t, 5, proposals[0].Nonce)
+		assert.EqualValues(t, 6, proposals[1].Nonce)
+	}
+}
@@ -0,0 +1,76 @@
+package safe
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// ErrInvalidSignature is returned if signature can't be recovered
+var ErrInvalidSignature = errors.New("invalid signature")
+
+const (
+	// signatureLength is a length of `r || s || v` signature
+	signatureLength = 65
+	// recoveryIDOffset is a position of v in signature
+	recoveryIDOffset = 64
+)
+
+// Sign signs Safe transaction hash, the way Safe expects ECDSA signature of an owner
+func Sign(safeTxHash common.Hash, key *ecdsa.PrivateKey) ([]byte, error) {
+	signature, err := crypto.Sign(safeTxHash.Bytes(), key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign safe transaction hash")
+	}
+	signature[recoveryIDOffset] += 27
+	return signature, nil
+}
+
+// Recover returns owner who signed Safe transaction hash. Signature is either ECDSA signature of the hash itself
+// with v of 27 or 28, or `eth_sign` signature of the hash with v increased by 4, as Safe accepts both.
+func Recover(safeTxHash common.Hash, signature []byte) (common.Address, error) {
+	if len(signature) != signatureLength {
+		return common.Address{}, ErrInvalidSignature
+	}
+
+	hash := safeTxHash.Bytes()
+	sig := make([]byte, len(signature))
+	copy(sig, signature)
+	v := sig[recoveryIDOffset]
+	switch {
+	case v == 27 || v == 28:
+		sig[recoveryIDOffset] = v - 27
+	case v == 31 || v == 32:
+		sig[recoveryIDOffset] = v - 31
+		hash = crypto.Keccak256([]byte("\x19Ethereum Signed Message:\n32"), hash)
+	default:
+		return common.Address{}, ErrInvalidSignature
+	}
+
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, ErrInvalidSignature
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// Pack concatenates signatures by owner, ordered by owner address as Safe requires
+func Pack(signatures map[common.Address][]byte) []byte {
+	owners := make([]common.Address, 0, len(signatures))
+	for owner := range signatures {
+		owners = append(owners, owner)
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		return bytes.Compare(owners[i].Bytes(), owners[j].Bytes()) < 0
+	})
+
+	packed := make([]byte, 0, len(owners)*signatureLength)
+	for _, owner := range owners {
+		packed = append(packed, signatures[owner]...)
+	}
+	return packed
+}
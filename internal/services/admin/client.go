@@ -0,0 +1,133 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/tokend/erc20-withdraw-svc/internal/hold"
+	"github.com/tokend/erc20-withdraw-svc/internal/safe"
+	"github.com/tokend/erc20-withdraw-svc/internal/timelock"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// Client is admin API client authorized as a reviewer
+type Client struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+// NewClient creates client of admin API served at endpoint
+func NewClient(client *http.Client, endpoint, token string) *Client {
+	return &Client{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		token:    token,
+		client:   client,
+	}
+}
+
+// List returns all held requests
+func (c *Client) List() ([]hold.Request, error) {
+	var result []hold.Request
+	if err := c.do(http.MethodGet, heldPath, nil, &result); err != nil {
+		return nil, errors.Wrap(err, "failed to list held requests")
+	}
+	return result, nil
+}
+
+// Decide approves or rejects held request on behalf of the reviewer
+func (c *Client) Decide(id string, approve bool, comment string) (*hold.Request, error) {
+	action := "reject"
+	if approve {
+		action = "approve"
+	}
+
+	var result hold.Request
+	if err := c.do(http.MethodPost, heldPath+"/"+id+"/"+action, Decision{Comment: comment}, &result); err != nil {
+		return nil, errors.Wrap(err, "failed to decide on held request", logan.F{"request_id": id})
+	}
+	return &result, nil
+}
+
+// ListTimelocks returns all time locked requests
+func (c *Client) ListTimelocks() ([]timelock.Lock, error) {
+	var result []timelock.Lock
+	if err := c.do(http.MethodGet, timelocksPath, nil, &result); err != nil {
+		return nil, errors.Wrap(err, "failed to list time locks")
+	}
+	return result, nil
+}
+
+// CancelTimelock cancels time locked request on behalf of the reviewer
+func (c *Client) CancelTimelock(id string, comment string) (*timelock.Lock, error) {
+	var result timelock.Lock
+	if err := c.do(http.MethodPost, timelocksPath+"/"+id+"/cancel", Decision{Comment: comment}, &result); err != nil {
+		return nil, errors.Wrap(err, "failed to cancel time lock", logan.F{"request_id": id})
+	}
+	return &result, nil
+}
+
+// ListSafe returns all Safe transactions proposed for withdrawals
+func (c *Client) ListSafe() ([]safe.Proposal, error) {
+	var result []safe.Proposal
+	if err := c.do(http.MethodGet, safePath, nil, &result); err != nil {
+		return nil, errors.Wrap(err, "failed to list safe transactions")
+	}
+	return result, nil
+}
+
+// ConfirmSafe submits signature of Safe owner confirming Safe transaction of the request
+func (c *Client) ConfirmSafe(id string, confirmation Confirmation) (*safe.Proposal, error) {
+	var result safe.Proposal
+	if err := c.do(http.MethodPost, safePath+"/"+id+"/confirm", confirmation, &result); err != nil {
+		return nil, errors.Wrap(err, "failed to confirm safe transaction", logan.F{"request_id": id})
+	}
+	return &result, nil
+}
+
+// DiscardSafe removes Safe transaction of the request on behalf of the reviewer
+func (c *Client) DiscardSafe(id string, comment string) (*safe.Proposal, error) {
+	var result safe.Proposal
+	if err := c.do(http.MethodPost, safePath+"/"+id+"/discard", Decision{Comment: comment}, &result); err != nil {
+		return nil, errors.Wrap(err, "failed to discard safe transaction", logan.F{"request_id": id})
+	}
+	return &result, nil
+}
+
+func (c *Client) do(method, path string, body, result interface{}) error {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return errors.Wrap(err, "failed to marshal request")
+		}
+	}
+
+	r, err := http.NewRequest(method, c.endpoint+path, &buf)
+	if err != nil {
+		return errors.Wrap(err, "failed to prepare request")
+	}
+	r.Header.Set("Authorization", "Bearer "+c.token)
+	r.Header.Set("Content-Type", "application/json")
+
+	response, err := c.client.Do(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform http request")
+	}
+	defer response.Body.Close()
+
+	respBB, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read response body")
+	}
+	if response.StatusCode != http.StatusOK {
+		return errors.From(errors.New(strings.TrimSpace(string(respBB))), logan.F{
+			"status_code": response.StatusCode,
+		})
+	}
+
+	return errors.Wrap(json.Unmarshal(respBB, result), "failed to unmarshal response")
+}
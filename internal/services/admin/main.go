@@ -0,0 +1,47 @@
+package admin
+
+import (
+	"github.com/tokend/erc20-withdraw-svc/internal/audit"
+	"github.com/tokend/erc20-withdraw-svc/internal/config"
+	"github.com/tokend/erc20-withdraw-svc/internal/hold"
+	"github.com/tokend/erc20-withdraw-svc/internal/horizon/ratelimit"
+	"github.com/tokend/erc20-withdraw-svc/internal/safe"
+	"github.com/tokend/erc20-withdraw-svc/internal/timelock"
+	"gitlab.com/distributed_lab/logan/v3"
+)
+
+// Service is struct representing admin API service
+type Service struct {
+	log       *logan.Entry
+	config    config.AdminConfig
+	holds     *hold.Store
+	timelocks *timelock.Store
+	proposals *safe.Store
+	auditor   audit.Recorder
+	limiter   *ratelimit.Limiter
+}
+
+// Opts contain parameters required to build service
+type Opts struct {
+	Log       *logan.Entry
+	Config    config.AdminConfig
+	Holds     *hold.Store
+	Timelocks *timelock.Store
+	Proposals *safe.Store
+	Auditor   audit.Recorder
+	// Limiter is a Horizon rate limiter, its counters are exposed as metrics
+	Limiter *ratelimit.Limiter
+}
+
+// New creates new admin API service
+func New(opts Opts) *Service {
+	return &Service{
+		log:       opts.Log.WithField("service", "admin"),
+		config:    opts.Config,
+		holds:     opts.Holds,
+		timelocks: opts.Timelocks,
+		proposals: opts.Proposals,
+		auditor:   opts.Auditor,
+		limiter:   opts.Limiter,
+	}
+}
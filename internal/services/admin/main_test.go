@@ -0,0 +1,217 @@
+package admin
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tokend/erc20-withdraw-svc/internal/audit"
+	"github.com/tokend/erc20-withdraw-svc/internal/config"
+	"github.com/tokend/erc20-withdraw-svc/internal/hold"
+	"github.com/tokend/erc20-withdraw-svc/internal/storage"
+	"github.com/tokend/erc20-withdraw-svc/internal/timelock"
+	"gitlab.com/distributed_lab/logan/v3"
+)
+
+const token = "alice-token"
+
+// recorder keeps audit events in memory
+type recorder struct {
+	mu     sync.Mutex
+	events []audit.Event
+}
+
+func (r *recorder) Record(event audit.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// env is admin API served over stores in temporary dir
+type env struct {
+	t         *testing.T
+	dir       string
+	holds     *hold.Store
+	timelocks *timelock.Store
+	auditor   *recorder
+	server    *httptest.Server
+}
+
+func newEnv(t *testing.T) *env {
+	dir, err := ioutil.TempDir("", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	holds, err := hold.New(storage.NewFile(dir, "held.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	timelocks, err := timelock.New(storage.NewFile(dir, "timelocks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditor := &recorder{}
+	service := New(Opts{
+		Log:       logan.New(),
+		Config:    config.AdminConfig{Reviewers: map[string]string{"alice": token}},
+		Holds:     holds,
+		Timelocks: timelocks,
+		Auditor:   auditor,
+	})
+
+	return &env{
+		t:         t,
+		dir:       dir,
+		holds:     holds,
+		timelocks: timelocks,
+		auditor:   auditor,
+		server:    httptest.NewServer(service.router()),
+	}
+}
+
+func (e *env) Close() {
+	e.server.Close()
+	os.RemoveAll(e.dir)
+}
+
+// do sends request with authorization header, if it is not empty, and decodes response into v, if it is not nil
+func (e *env) do(method, path, authorization, body string, v interface{}) int {
+	r, err := http.NewRequest(method, e.server.URL+path, strings.NewReader(body))
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	if authorization != "" {
+		r.Header.Set("Authorization", authorization)
+	}
+	response, err := http.DefaultClient.Do(r)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	if v != nil && response.StatusCode == http.StatusOK {
+		assert.NoError(e.t, json.NewDecoder(response.Body).Decode(v))
+	}
+	return response.StatusCode
+}
+
+func TestHeld(t *testing.T) {
+	e := newEnv(t)
+	defer e.Close()
+	assert.NoError(t, e.holds.Hold(hold.Request{ID: "1", Asset: "TKN", Amount: "10"}))
+	assert.NoError(t, e.holds.Hold(hold.Request{ID: "2", Asset: "TKN", Amount: "20"}))
+
+	var list []hold.Request
+	assert.Equal(t, http.StatusOK, e.do(http.MethodGet, "/held", "Bearer "+token, "", &list))
+	assert.Len(t, list, 2)
+	var request hold.Request
+	assert.Equal(t, http.StatusOK, e.do(http.MethodGet, "/held/1", "Bearer "+token, "", &request))
+	assert.Equal(t, hold.StateHeld, request.State)
+	assert.Equal(t, http.StatusNotFound, e.do(http.MethodGet, "/held/3", "Bearer "+token, "", nil))
+
+	assert.Equal(t, http.StatusOK, e.do(http.MethodPost, "/held/1/approve", "Bearer "+token, `{"comment":"checked"}`, &request))
+	assert.Equal(t, hold.StateApproved, request.State)
+	assert.Equal(t, "alice", request.DecidedBy)
+	assert.Equal(t, "checked", request.Comment)
+	// decision can't be changed
+	assert.Equal(t, http.StatusConflict, e.do(http.MethodPost, "/held/1/reject", "Bearer "+token, "", nil))
+
+	assert.Equal(t, http.StatusOK, e.do(http.MethodPost, "/held/2/reject", "Bearer "+token, "", &request))
+	assert.Equal(t, hold.StateRejected, request.State)
+	assert.Equal(t, http.StatusNotFound, e.do(http.MethodPost, "/held/3/approve", "Bearer "+token, "", nil))
+	assert.Equal(t, http.StatusNotFound, e.do(http.MethodGet, "/held/1/approve", "Bearer "+token, "", nil))
+
+	stored, ok := e.holds.Get("2")
+	if assert.True(t, ok) {
+		assert.Equal(t, hold.StateRejected, stored.State)
+	}
+	if assert.Len(t, e.auditor.events, 2) {
+		assert.Equal(t, auditReviewDecision, e.auditor.events[0].Type)
+		assert.Equal(t, "1", e.auditor.events[0].RequestID)
+		assert.Equal(t, hold.StateApproved, e.auditor.events[0].Details["decision"])
+		assert.Equal(t, "alice", e.auditor.events[0].Details["reviewer"])
+		assert.Equal(t, hold.StateRejected, e.auditor.events[1].Details["decision"])
+	}
+}
+
+func TestHeld_BadBody(t *testing.T) {
+	e := newEnv(t)
+	defer e.Close()
+	assert.NoError(t, e.holds.Hold(hold.Request{ID: "1", Asset: "TKN"}))
+
+	assert.Equal(t, http.StatusBadRequest, e.do(http.MethodPost, "/held/1/approve", "Bearer "+token, `{"comment":`, nil))
+	request, ok := e.holds.Get("1")
+	if assert.True(t, ok) {
+		assert.Equal(t, hold.StateHeld, request.State)
+	}
+	assert.Empty(t, e.auditor.events)
+}
+
+func TestUnauthorized(t *testing.T) {
+	e := newEnv(t)
+	defer e.Close()
+	assert.NoError(t, e.holds.Hold(hold.Request{ID: "1", Asset: "TKN"}))
+
+	for name, authorization := range map[string]string{
+		"missing":      "",
+		"wrong token":  "Bearer bob-token",
+		"empty token":  "Bearer ",
+		"bare token":   token,
+		"other scheme": "Basic " + token,
+	} {
+		assert.Equal(t, http.StatusUnauthorized, e.do(http.MethodGet, "/held", authorization, "", nil), name)
+		assert.Equal(t, http.StatusUnauthorized, e.do(http.MethodPost, "/held/1/approve", authorization, "", nil), name)
+	}
+
+	request, ok := e.holds.Get("1")
+	if assert.True(t, ok) {
+		assert.Equal(t, hold.StateHeld, request.State)
+	}
+	assert.Empty(t, e.auditor.events)
+
+	// metrics are served without token
+	assert.Equal(t, http.StatusOK, e.do(http.MethodGet, "/metrics", "", "", nil))
+}
+
+func TestTimelockCancel(t *testing.T) {
+	e := newEnv(t)
+	defer e.Close()
+	now := time.Now().UTC()
+	for _, id := range []string{"1", "2"} {
+		_, err := e.timelocks.Lock(timelock.Lock{ID: id, Asset: "TKN", LockedAt: now, SendAt: now.Add(time.Hour)})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, e.timelocks.Release("2"))
+
+	var list []timelock.Lock
+	assert.Equal(t, http.StatusOK, e.do(http.MethodGet, "/timelocks", "Bearer "+token, "", &list))
+	assert.Len(t, list, 2)
+	assert.Equal(t, http.StatusUnauthorized, e.do(http.MethodPost, "/timelocks/1/cancel", token, "", nil))
+
+	var lock timelock.Lock
+	assert.Equal(t, http.StatusOK, e.do(http.MethodPost, "/timelocks/1/cancel", "Bearer "+token, `{"comment":"suspicious"}`, &lock))
+	assert.Equal(t, timelock.StateCancelled, lock.State)
+	assert.Equal(t, "alice", lock.CancelledBy)
+	assert.Equal(t, "suspicious", lock.Comment)
+	assert.Equal(t, http.StatusOK, e.do(http.MethodGet, "/timelocks/1", "Bearer "+token, "", &lock))
+	assert.Equal(t, timelock.StateCancelled, lock.State)
+
+	// only active lock can be cancelled
+	assert.Equal(t, http.StatusConflict, e.do(http.MethodPost, "/timelocks/1/cancel", "Bearer "+token, "", nil))
+	assert.Equal(t, http.StatusConflict, e.do(http.MethodPost, "/timelocks/2/cancel", "Bearer "+token, "", nil))
+	assert.Equal(t, http.StatusNotFound, e.do(http.MethodPost, "/timelocks/3/cancel", "Bearer "+token, "", nil))
+	assert.Equal(t, http.StatusBadRequest, e.do(http.MethodPost, "/timelocks/2/cancel", "Bearer "+token, `{`, nil))
+
+	if assert.Len(t, e.auditor.events, 1) {
+		assert.Equal(t, auditTimelockCancelled, e.auditor.events[0].Type)
+		assert.Equal(t, "1", e.auditor.events[0].RequestID)
+		assert.Equal(t, "alice", e.auditor.events[0].Details["reviewer"])
+	}
+}
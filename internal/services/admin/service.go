@@ -0,0 +1,360 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/tokend/erc20-withdraw-svc/internal/audit"
+	"github.com/tokend/erc20-withdraw-svc/internal/hold"
+	"github.com/tokend/erc20-withdraw-svc/internal/safe"
+	"github.com/tokend/erc20-withdraw-svc/internal/timelock"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/running"
+)
+
+const (
+	auditReviewDecision    = "review_decision"
+	auditTimelockCancelled = "timelock_cancelled"
+	auditSafeConfirmed     = "safe_confirmed"
+	auditSafeDiscarded     = "safe_discarded"
+
+	heldPath      = "/held"
+	timelocksPath = "/timelocks"
+	safePath      = "/safe"
+	metricsPath   = "/metrics"
+)
+
+// Decision is a body of approve and reject requests
+type Decision struct {
+	Comment string `json:"comment"`
+}
+
+// Confirmation is a body of Safe transaction confirmation request
+type Confirmation struct {
+	// SafeTxHash is a hash of confirmed Safe transaction, so confirmation of replaced proposal is refused
+	SafeTxHash string `json:"safe_tx_hash"`
+	Signature  string `json:"signature"`
+}
+
+// Run serves admin API until ctx is cancelled, does nothing if API address is not configured
+func (s *Service) Run(ctx context.Context) {
+	if s.config.Address == "" {
+		s.log.Info("admin API address is not set, API is disabled")
+		return
+	}
+
+	running.Server(ctx, s.log, running.ServerConfig{
+		Address:             s.config.Address,
+		RequestWriteTimeout: 30 * time.Second,
+		ShutdownTimeout:     5 * time.Second,
+	}, s.router())
+}
+
+func (s *Service) router() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(heldPath, s.authorized(s.listHeld))
+	mux.HandleFunc(heldPath+"/", s.authorized(s.held))
+	mux.HandleFunc(timelocksPath, s.authorized(s.listTimelocks))
+	mux.HandleFunc(timelocksPath+"/", s.authorized(s.timelock))
+	mux.HandleFunc(safePath, s.authorized(s.listSafe))
+	mux.HandleFunc(safePath+"/", s.authorized(s.safe))
+	// metrics are counters only, so monitoring may scrape them without reviewer token
+	mux.HandleFunc(metricsPath, s.metrics)
+	return mux
+}
+
+// authorized resolves reviewer by bearer token and passes it to the handler
+func (s *Service) authorized(handler func(w http.ResponseWriter, r *http.Request, reviewer string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		token := strings.TrimPrefix(header, "Bearer ")
+		// bare token is refused, so it is not accepted from headers of other schemes
+		if token != header {
+			for reviewer, expected := range s.config.Reviewers {
+				if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+					handler(w, r, reviewer)
+					return
+				}
+			}
+		}
+
+		s.log.WithField("remote_addr", r.RemoteAddr).Warn("unauthorized admin API request")
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	}
+}
+
+func (s *Service) listHeld(w http.ResponseWriter, r *http.Request, _ string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.render(w, http.StatusOK, s.holds.List())
+}
+
+// held serves `/held/{id}` and `/held/{id}/{approve|reject}`
+func (s *Service) held(w http.ResponseWriter, r *http.Request, reviewer string) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, heldPath), "/"), "/")
+	id := parts[0]
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		request, ok := s.holds.Get(id)
+		if !ok {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		s.render(w, http.StatusOK, request)
+	case len(parts) == 2 && r.Method == http.MethodPost && (parts[1] == "approve" || parts[1] == "reject"):
+		s.decide(w, r, id, parts[1] == "approve", reviewer)
+	default:
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	}
+}
+
+func (s *Service) decide(w http.ResponseWriter, r *http.Request, id string, approve bool, reviewer string) {
+	var decision Decision
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&decision); err != nil {
+			http.Error(w, "failed to decode request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	request, err := s.holds.Decide(id, approve, reviewer, decision.Comment)
+	switch err {
+	case nil:
+	case hold.ErrNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case hold.ErrAlreadyDecided:
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	default:
+		s.log.WithError(err).WithField("request_id", id).Error("failed to decide on held request")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	s.auditor.Record(audit.Event{
+		Type:      auditReviewDecision,
+		RequestID: request.ID,
+		Asset:     request.Asset,
+		Details: map[string]interface{}{
+			"decision": request.State,
+			"reviewer": reviewer,
+			"comment":  request.Comment,
+		},
+	})
+
+	s.render(w, http.StatusOK, request)
+}
+
+func (s *Service) listTimelocks(w http.ResponseWriter, r *http.Request, _ string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.render(w, http.StatusOK, s.timelocks.List())
+}
+
+// timelock serves `/timelocks/{id}` and `/timelocks/{id}/cancel`
+func (s *Service) timelock(w http.ResponseWriter, r *http.Request, reviewer string) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, timelocksPath), "/"), "/")
+	id := parts[0]
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		lock, ok := s.timelocks.Get(id)
+		if !ok {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		s.render(w, http.StatusOK, lock)
+	case len(parts) == 2 && r.Method == http.MethodPost && parts[1] == "cancel":
+		s.cancel(w, r, id, reviewer)
+	default:
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	}
+}
+
+func (s *Service) cancel(w http.ResponseWriter, r *http.Request, id string, reviewer string) {
+	var decision Decision
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&decision); err != nil {
+			http.Error(w, "failed to decode request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	lock, err := s.timelocks.Cancel(id, reviewer, decision.Comment)
+	switch err {
+	case nil:
+	case timelock.ErrNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case timelock.ErrNotCancelled:
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	default:
+		s.log.WithError(err).WithField("request_id", id).Error("failed to cancel time locked request")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	s.auditor.Record(audit.Event{
+		Type:      auditTimelockCancelled,
+		RequestID: lock.ID,
+		Asset:     lock.Asset,
+		Details: map[string]interface{}{
+			"reviewer": reviewer,
+			"comment":  lock.Comment,
+		},
+	})
+
+	s.render(w, http.StatusOK, lock)
+}
+
+func (s *Service) listSafe(w http.ResponseWriter, r *http.Request, _ string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.render(w, http.StatusOK, s.proposals.List())
+}
+
+// safe serves `/safe/{id}`, `/safe/{id}/confirm` and `/safe/{id}/discard`
+func (s *Service) safe(w http.ResponseWriter, r *http.Request, reviewer string) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, safePath), "/"), "/")
+	id := parts[0]
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		proposal, ok := s.proposals.Get(id)
+		if !ok {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		s.render(w, http.StatusOK, proposal)
+	case len(parts) == 2 && r.Method == http.MethodPost && parts[1] == "confirm":
+		s.confirm(w, r, id, reviewer)
+	case len(parts) == 2 && r.Method == http.MethodPost && parts[1] == "discard":
+		s.discard(w, r, id, reviewer)
+	default:
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	}
+}
+
+// confirm stores signature of Safe owner, owner is recovered from the signature and checked against the Safe before execution
+func (s *Service) confirm(w http.ResponseWriter, r *http.Request, id string, reviewer string) {
+	var confirmation Confirmation
+	if err := json.NewDecoder(r.Body).Decode(&confirmation); err != nil {
+		http.Error(w, "failed to decode request body", http.StatusBadRequest)
+		return
+	}
+	signature, err := hexutil.Decode(confirmation.Signature)
+	if err != nil {
+		http.Error(w, "signature must be a hex string", http.StatusBadRequest)
+		return
+	}
+	owner, err := safe.Recover(common.HexToHash(confirmation.SafeTxHash), signature)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	proposal, err := s.proposals.Confirm(id, confirmation.SafeTxHash, owner.String(), confirmation.Signature)
+	switch err {
+	case nil:
+	case safe.ErrNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case safe.ErrAlreadyConfirmed:
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	default:
+		s.log.WithError(err).WithField("request_id", id).Error("failed to confirm safe transaction")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	s.auditor.Record(audit.Event{
+		Type:      auditSafeConfirmed,
+		RequestID: proposal.ID,
+		Asset:     proposal.Asset,
+		Details: map[string]interface{}{
+			"reviewer":     reviewer,
+			"owner":        owner.String(),
+			"safe_tx_hash": proposal.SafeTxHash,
+		},
+	})
+
+	s.render(w, http.StatusOK, proposal)
+}
+
+// discard removes proposal which won't be executed, so it does not hold Safe nonce. Later proposals
+// are created again to fill the nonce, as well as proposal of the request itself if it is still pending
+func (s *Service) discard(w http.ResponseWriter, r *http.Request, id string, reviewer string) {
+	var decision Decision
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&decision); err != nil {
+			http.Error(w, "failed to decode request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	proposal, err := s.proposals.Remove(id)
+	switch err {
+	case nil:
+	case safe.ErrNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	default:
+		s.log.WithError(err).WithField("request_id", id).Error("failed to discard safe transaction")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	s.auditor.Record(audit.Event{
+		Type:      auditSafeDiscarded,
+		RequestID: proposal.ID,
+		Asset:     proposal.Asset,
+		Details: map[string]interface{}{
+			"reviewer":     reviewer,
+			"comment":      decision.Comment,
+			"safe_tx_hash": proposal.SafeTxHash,
+		},
+	})
+
+	s.render(w, http.StatusOK, proposal)
+}
+
+// metrics renders counters of Horizon rate limiter by budget
+func (s *Service) metrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	result := map[string]interface{}{}
+	if s.limiter != nil {
+		result["horizon_rate_limit"] = s.limiter.Stats()
+	}
+	s.render(w, http.StatusOK, result)
+}
+
+func (s *Service) render(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.log.WithError(err).WithFields(logan.F{"status": status}).Error("failed to render response")
+	}
+}
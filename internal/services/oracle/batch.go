@@ -0,0 +1,115 @@
+package oracle
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/tokend/erc20-withdraw-svc/internal/token"
+	"gitlab.com/distributed_lab/logan/v3"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+// queued is a withdrawal which passed all checks and waits for its batch to be sent
+type queued struct {
+	request       regources.ReviewableRequest
+	details       *regources.CreateWithdrawRequest
+	address       string
+	transfer      token.Transfer
+	amountDetails map[string]interface{}
+	private       bool
+}
+
+func (s *Service) isQueued(requestID string) bool {
+	for _, item := range s.batch {
+		if item.request.ID == requestID {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueue adds withdrawal to the batch, it is reserved in limits right away,
+// so withdrawals queued within the same window can't exceed them together
+func (s *Service) enqueue(item queued) {
+	if len(s.batch) == 0 {
+		s.batchOpened = time.Now()
+	}
+	s.batch = append(s.batch, item)
+	s.reserveLimits(item.request, item.details, item.address)
+
+	s.log.WithFields(logan.F{
+		"request_id": item.request.ID,
+		"batch_size": len(s.batch),
+	}).Info("request is queued for batch transfer")
+}
+
+// flushBatch sends queued withdrawals once batch window is over or batch is full.
+// Requests stay pending in TokenD while queued, so they are collected again if service restarts.
+func (s *Service) flushBatch(ctx context.Context) {
+	if s.multisend == nil || len(s.batch) == 0 {
+		return
+	}
+	if len(s.batch) < s.batchCfg.MaxSize && time.Since(s.batchOpened) < s.batchCfg.Window {
+		return
+	}
+
+	items := s.batch
+	s.batch = nil
+
+	approved := make([]queued, 0, len(items))
+	for _, item := range items {
+		err := s.approveRequest(ctx, item.request, taskCheckTxSentSuccess, taskTryTransfer, item.amountDetails)
+		if err != nil {
+			s.log.WithError(err).WithField("request_id", item.request.ID).Warn("failed to review request first time, leaving it out of batch")
+			// request is still pending, so it is queued and reserved again once collected
+			s.releaseLimits(item.request)
+			continue
+		}
+		approved = append(approved, item)
+	}
+	if len(approved) == 0 {
+		return
+	}
+
+	// whole batch goes through relay if any of its withdrawals has to
+	var private bool
+	transfers := make([]token.Transfer, 0, len(approved))
+	for _, item := range approved {
+		transfers = append(transfers, item.transfer)
+		private = private || item.private
+	}
+	s.log.WithField("batch_size", len(transfers)).Info("going to send batch transfer")
+
+	transaction, err := s.callBatch(s.sendContext(ctx, private), transfers)
+	if err != nil {
+		s.log.WithError(err).Error("Batch transfer failed - rejecting withdraw requests")
+		for _, item := range approved {
+			s.releaseLimits(item.request)
+			if err := s.permanentReject(ctx, item.request, transferFailed); err != nil {
+				s.log.WithError(err).WithField("request_id", item.request.ID).Error("failed to reject request")
+			}
+		}
+		return
+	}
+
+	for i, item := range approved {
+		s.recordLimits(item.request, item.details, item.address)
+		// log index of transfer is only known once transaction is mined, verifier publishes it as eth_log_index
+		sent := sentDetails(item.amountDetails, transaction)
+		sent["batch_position"] = i
+		if err := s.approveRequest(ctx, item.request, taskCheckTxConfirmed, taskCheckTxSentSuccess, sent); err != nil {
+			s.log.WithError(err).WithField("request_id", item.request.ID).Error("failed to review request second time")
+		}
+	}
+}
+
+func (s *Service) callBatch(ctx context.Context, transfers []token.Transfer) (*types.Transaction, error) {
+	opts, err := s.transactOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// every transfer is budgeted as a separate one, so fee charged per request covers it
+	opts.GasLimit = s.transferCfg.GasLimit * uint64(len(transfers))
+	return s.multisend.Send(opts, transfers)
+}
@@ -0,0 +1,35 @@
+package oracle
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/tokend/erc20-withdraw-svc/internal/fees"
+	"github.com/tokend/erc20-withdraw-svc/internal/token"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// fee returns amount in token units deducted from withdrawal to cover gas
+func (s *Service) fee(ctx context.Context) (*big.Int, error) {
+	cfg, ok := s.feesCfg.Assets[s.asset.ID]
+	// unique item can't be split to pay the fee
+	if !ok || s.standard == token.StandardERC721 {
+		return new(big.Int), nil
+	}
+
+	result, _ := s.converter.ToToken(uint64(cfg.Flat))
+	if s.rates == nil {
+		return result, nil
+	}
+
+	rate, err := s.rates.Rate(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get rate")
+	}
+
+	return result.Add(result, fees.GasFee(s.transferCfg.GasLimit, s.gasPrice(), rate, s.converter.Decimals())), nil
+}
+
+func (s *Service) gasPrice() *big.Int {
+	return FromGwei(big.NewInt(s.transferCfg.GasPrice))
+}
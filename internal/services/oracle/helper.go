@@ -7,7 +7,8 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/tokend/erc20-withdraw-svc/internal/services/watchlist"
+	"github.com/tokend/erc20-withdraw-svc/internal/config"
+	"github.com/tokend/erc20-withdraw-svc/internal/token"
 	"gitlab.com/distributed_lab/logan/v3"
 	"gitlab.com/distributed_lab/logan/v3/errors"
 	regources "gitlab.com/tokend/regources/generated"
@@ -24,56 +25,177 @@ const (
 	//page size
 	requestPageSizeLimit = 10
 
-	invalidDetails       = "Invalid creator details"
-	invalidTargetAddress = "Invalid target address"
-	tooSmallAmount       = "Withdrawn amount too small"
-	transferFailed       = "Transfer failed"
+	invalidDetails         = "Invalid creator details"
+	invalidTargetAddress   = "Invalid target address"
+	tooSmallAmount         = "Withdrawn amount too small"
+	inexactAmount          = "Withdrawn amount can't be converted exactly"
+	feeNotCovered          = "Withdrawn amount does not cover fee"
+	invalidTokenID         = "Invalid token id"
+	invalidNFTAmount       = "Non fungible token withdrawal amount must be 1"
+	transferFailed         = "Transfer failed"
+	deniedAddress          = "Destination address is not allowed"
+	exceedsReviewThreshold = "Withdrawal exceeds review threshold"
+	rejectedByReviewer     = "Rejected by reviewer"
+	timelockCancelled      = "Cancelled during time lock"
 )
 
 type PreSentDetails struct {
 	TargetAddress string `json:"address"`
+	// TokenID is an id of non fungible token being withdrawn
+	TokenID string `json:"token_id"`
 }
 
 func (s *Service) sendWithdraw(ctx context.Context, request regources.ReviewableRequest, details *regources.CreateWithdrawRequest) error {
+	fields := logan.F{"request_id": request.ID}
+	if s.isQueued(request.ID) {
+		return nil
+	}
+
+	held, isHeld := s.holds.Get(request.ID)
+	if isHeld {
+		stopped, err := s.processHeld(ctx, request, held)
+		if stopped || err != nil {
+			return err
+		}
+	}
+
 	detailsbb := []byte(details.Attributes.CreatorDetails)
 	withdrawDetails := PreSentDetails{}
-	fields := logan.F{"request_id": request.ID}
 	err := json.Unmarshal(detailsbb, &withdrawDetails)
 	if err != nil {
 		s.log.WithFields(fields).WithError(err).Warn("Unable to unmarshal creator details")
 		return s.permanentReject(ctx, request, invalidDetails)
 	}
 
-	if withdrawDetails.TargetAddress == "" {
+	if !common.IsHexAddress(withdrawDetails.TargetAddress) {
 		s.log.WithFields(fields).
 			WithField("creator_details", details.Attributes.CreatorDetails).
-			WithError(err).
-			Warn("address missing")
+			Warn("target address missing or invalid")
 		return s.permanentReject(ctx, request, invalidTargetAddress)
 	}
 
-	err = s.approveRequest(ctx, request, taskCheckTxSentSuccess, taskTryTransfer, map[string]interface{}{})
+	transferAmount, remainder := s.converter.ToToken(uint64(details.Attributes.Amount))
+	if remainder != 0 && s.withdrawCfg.Remainder != config.RemainderPolicyRoundDown {
+		s.log.WithFields(fields).WithField("remainder", remainder).Warn("amount can't be converted exactly")
+		return s.permanentReject(ctx, request, inexactAmount)
+	}
+	if transferAmount.Sign() == 0 {
+		return s.permanentReject(ctx, request, tooSmallAmount)
+	}
+
+	var tokenID *big.Int
+	if s.standard == token.StandardERC721 {
+		var ok bool
+		tokenID, ok = new(big.Int).SetString(withdrawDetails.TokenID, 10)
+		if !ok || tokenID.Sign() < 0 {
+			s.log.WithFields(fields).WithField("token_id", withdrawDetails.TokenID).Warn("invalid token id")
+			return s.permanentReject(ctx, request, invalidTokenID)
+		}
+		if transferAmount.Cmp(big.NewInt(1)) != 0 {
+			return s.permanentReject(ctx, request, invalidNFTAmount)
+		}
+	}
+	// request approved by reviewer has already been checked by human
+	if !isHeld {
+		stopped, err := s.checkBeforeSend(ctx, request, details, withdrawDetails.TargetAddress)
+		if stopped || err != nil {
+			return errors.Wrap(err, "failed to check request before sending", fields)
+		}
+	}
+
+	stopped, err := s.awaitTimelock(ctx, request, details, withdrawDetails.TargetAddress)
+	if stopped || err != nil {
+		return errors.Wrap(err, "failed to process time lock", fields)
+	}
+
+	fee, err := s.fee(ctx)
 	if err != nil {
-		return errors.Wrap(err, "failed to review request first time", fields)
+		return errors.Wrap(err, "failed to calculate fee", fields)
+	}
+	sendAmount := new(big.Int).Sub(transferAmount, fee)
+	if sendAmount.Sign() <= 0 {
+		s.log.WithFields(fields).WithField("fee", fee).Info("withdrawn amount does not cover fee")
+		return s.permanentReject(ctx, request, feeNotCovered)
+	}
+	if err := s.checkSupply(ctx); err != nil {
+		return errors.Wrap(err, "supply check failed, refusing to mint", fields)
 	}
 
-	transferAmount := prepareAmount(s.asset, s.decimals, uint64(details.Attributes.Amount))
-	if transferAmount.Uint64() == 0 {
-		return s.permanentReject(ctx, request, tooSmallAmount)
+	amountDetails := map[string]interface{}{
+		"amount":    sendAmount.String(),
+		"fee":       fee.String(),
+		"remainder": regources.Amount(remainder).String(),
+	}
+	if tokenID != nil {
+		amountDetails["token_id"] = tokenID.String()
+	}
+	var ref common.Hash
+	if s.mode == token.ModeBridge {
+		ref, err = token.RequestRef(request.ID, request.Attributes.Hash)
+		if err != nil {
+			return errors.Wrap(err, "failed to build request ref", fields)
+		}
+		amountDetails["request_ref"] = ref.String()
+	}
+
+	transfer := token.Transfer{
+		To:      common.HexToAddress(withdrawDetails.TargetAddress),
+		Amount:  sendAmount,
+		TokenID: tokenID,
+		Ref:     ref,
+	}
+	proposal, stopped, err := s.awaitSafe(ctx, request, details, transfer)
+	if stopped || err != nil {
+		return errors.Wrap(err, "failed to process safe transaction", fields)
+	}
+	if proposal != nil {
+		// amount is fixed once Safe transaction is proposed, while fee may have changed since then
+		transfer.Amount, err = proposedAmount(proposal)
+		if err != nil {
+			return errors.Wrap(err, "failed to get proposed amount", fields)
+		}
+		amountDetails["amount"] = transfer.Amount.String()
+		amountDetails["fee"] = new(big.Int).Sub(transferAmount, transfer.Amount).String()
+		amountDetails["safe_tx_hash"] = proposal.SafeTxHash
+	}
+
+	if s.multisend != nil && proposal == nil {
+		s.enqueue(queued{
+			request:       request,
+			details:       details,
+			address:       withdrawDetails.TargetAddress,
+			transfer:      transfer,
+			private:       s.isPrivate(details),
+			amountDetails: amountDetails,
+		})
+		return nil
+	}
+
+	err = s.approveRequest(ctx, request, taskCheckTxSentSuccess, taskTryTransfer, amountDetails)
+	if err != nil {
+		return errors.Wrap(err, "failed to review request first time", fields)
 	}
 
 	s.log.WithFields(fields).Info("request is processing, going to transfer tokens")
 
-	transaction, err := s.callTransfer(ctx, transferAmount, withdrawDetails.TargetAddress)
+	var transaction *types.Transaction
+	if proposal != nil {
+		transaction, err = s.execSafe(s.sendContext(ctx, s.isPrivate(details)), proposal)
+	} else {
+		transaction, err = s.callTransfer(s.sendContext(ctx, s.isPrivate(details)), transfer)
+	}
 	if err != nil {
 		s.log.WithError(err).Error("Transfer failed - rejecting withdraw request")
+		if proposal != nil {
+			if _, err := s.proposals.Remove(request.ID); err != nil {
+				s.log.WithError(err).WithFields(fields).Error("failed to remove safe transaction")
+			}
+		}
 		return s.permanentReject(ctx, request, transferFailed)
 	}
+	s.recordLimits(request, details, withdrawDetails.TargetAddress)
 
-	err = s.approveRequest(ctx, request, taskCheckTxConfirmed, taskCheckTxSentSuccess, map[string]interface{}{
-		"eth_tx_hash": transaction.Hash().String(),
-		"amount":      transferAmount.String(),
-	})
+	err = s.approveRequest(ctx, request, taskCheckTxConfirmed, taskCheckTxSentSuccess, sentDetails(amountDetails, transaction))
 	if err != nil {
 		return errors.Wrap(err, "failed to review request second time", fields)
 	}
@@ -81,14 +203,59 @@ func (s *Service) sendWithdraw(ctx context.Context, request regources.Reviewable
 	return nil
 }
 
-func (s *Service) callTransfer(ctx context.Context, amount *big.Int, targetAddress string) (*types.Transaction, error) {
+// sentDetails are published once transaction delivering withdrawal is sent
+func sentDetails(amountDetails map[string]interface{}, transaction *types.Transaction) map[string]interface{} {
+	result := map[string]interface{}{
+		"eth_tx_hash": transaction.Hash().String(),
+	}
+	for _, key := range []string{"amount", "fee", "remainder", "request_ref", "safe_tx_hash"} {
+		if value, ok := amountDetails[key]; ok {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// checkBeforeSend runs checks withdrawal must pass before tokens are sent, returns true if request must not proceed
+func (s *Service) checkBeforeSend(
+	ctx context.Context, request regources.ReviewableRequest, details *regources.CreateWithdrawRequest, address string,
+) (bool, error) {
+	stopped, err := s.screen(ctx, request, details, address)
+	if stopped || err != nil {
+		return stopped, errors.Wrap(err, "failed to screen destination address")
+	}
+
+	stopped, err = s.checkLimits(request, details, address)
+	if stopped || err != nil {
+		return stopped, errors.Wrap(err, "failed to check withdrawal limits")
+	}
+
+	stopped, err = s.requireReview(request, details, address)
+	return stopped, errors.Wrap(err, "failed to hold request for review")
+}
+
+func (s *Service) callTransfer(ctx context.Context, transfer token.Transfer) (*types.Transaction, error) {
+	opts, err := s.transactOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.contract.Send(opts, transfer)
+}
+
+// transactOpts returns options of transaction sent from hot wallet with the next nonce,
+// which follows private transactions as well, as nodes don't know about them until they are mined
+func (s *Service) transactOpts(ctx context.Context) (*bind.TransactOpts, error) {
 	from := common.HexToAddress(s.transferCfg.Address)
-	to := common.HexToAddress(targetAddress)
 	nonce, err := s.client.PendingNonceAt(context.Background(), from)
 	if err != nil {
 		return nil, err
 	}
-	return s.contract.Transact(&bind.TransactOpts{
+	if s.held != nil {
+		if held, ok := s.held.HeldNonce(from); ok && held+1 > nonce {
+			nonce = held + 1
+		}
+	}
+	return &bind.TransactOpts{
 		Context: ctx,
 		From:    from,
 		Signer: func(signer types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
@@ -100,22 +267,7 @@ func (s *Service) callTransfer(ctx context.Context, amount *big.Int, targetAddre
 			return tx.WithSignature(signer, signature)
 		},
 		GasLimit: s.transferCfg.GasLimit,
-		GasPrice: FromGwei(big.NewInt(s.transferCfg.GasPrice)),
+		GasPrice: s.gasPrice(),
 		Nonce:    big.NewInt(int64(nonce)),
-	}, "transfer", to, amount)
-}
-
-func prepareAmount(asset watchlist.Details, dec uint32, am uint64) *big.Int {
-	trailingDigits := int64(asset.Attributes.TrailingDigits)
-	decimals := int64(dec)
-	var amount *big.Int
-	if trailingDigits > decimals {
-		amount = big.NewInt(1).
-			Quo(big.NewInt(int64(am)), big.NewInt(1).Exp(big.NewInt(10), big.NewInt(trailingDigits-decimals), nil))
-	} else {
-		amount = big.NewInt(1).
-			Mul(big.NewInt(int64(am)), big.NewInt(1).Exp(big.NewInt(10), big.NewInt(decimals-trailingDigits), nil))
-	}
-
-	return amount
+	}, nil
 }
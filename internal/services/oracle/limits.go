@@ -0,0 +1,96 @@
+package oracle
+
+import (
+	"github.com/tokend/erc20-withdraw-svc/internal/audit"
+	"github.com/tokend/erc20-withdraw-svc/internal/config"
+	"github.com/tokend/erc20-withdraw-svc/internal/limits"
+	"gitlab.com/distributed_lab/logan/v3"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+const (
+	auditLimitExceeded = "limit_exceeded"
+)
+
+// checkLimits checks withdrawal against asset limits, returns true if request must not proceed
+func (s *Service) checkLimits(
+	request regources.ReviewableRequest, details *regources.CreateWithdrawRequest, address string,
+) (bool, error) {
+	assetLimits, ok := s.limitsCfg.Assets[s.asset.ID]
+	if !ok {
+		return false, nil
+	}
+
+	violation := s.limits.Check(assetLimits, limitedWithdrawal(request, details, s.asset.ID, address))
+	if violation == nil {
+		return false, nil
+	}
+
+	fields := logan.F{
+		"request_id": request.ID,
+		"amount":     details.Attributes.Amount,
+		"violation":  violation.String(),
+	}
+	if !violation.Cap && s.limitsCfg.Policy == config.LimitsPolicyDefer {
+		s.log.WithFields(fields).Info("request exceeds withdrawal limits, deferring")
+		return true, nil
+	}
+
+	s.auditor.Record(audit.Event{
+		Type:      auditLimitExceeded,
+		RequestID: request.ID,
+		Asset:     s.asset.ID,
+		Details: map[string]interface{}{
+			"address":   address,
+			"amount":    details.Attributes.Amount.String(),
+			"violation": violation.String(),
+		},
+	})
+	s.log.WithFields(fields).Warn("request exceeds withdrawal limits, holding request for manual review")
+	return true, s.hold(request, details, address, "Withdrawal "+violation.String())
+}
+
+// reserveLimits accounts queued withdrawal in rolling limits until it is either sent or dropped from the queue
+func (s *Service) reserveLimits(request regources.ReviewableRequest, details *regources.CreateWithdrawRequest, address string) {
+	if _, ok := s.limitsCfg.Assets[s.asset.ID]; !ok {
+		return
+	}
+	s.limits.Reserve(limitedWithdrawal(request, details, s.asset.ID, address))
+}
+
+// releaseLimits drops reservation of withdrawal which was not sent
+func (s *Service) releaseLimits(request regources.ReviewableRequest) {
+	if _, ok := s.limitsCfg.Assets[s.asset.ID]; !ok {
+		return
+	}
+	s.limits.Release(request.ID)
+}
+
+// recordLimits accounts sent withdrawal in rolling limits
+func (s *Service) recordLimits(request regources.ReviewableRequest, details *regources.CreateWithdrawRequest, address string) {
+	if _, ok := s.limitsCfg.Assets[s.asset.ID]; !ok {
+		return
+	}
+
+	err := s.limits.Record(limitedWithdrawal(request, details, s.asset.ID, address))
+	if err != nil {
+		s.log.WithError(err).WithField("request_id", request.ID).Error("failed to account withdrawal in limits")
+	}
+}
+
+func limitedWithdrawal(
+	request regources.ReviewableRequest, details *regources.CreateWithdrawRequest, asset, address string,
+) limits.Withdrawal {
+	var requestor string
+	if request.Relationships.Requestor != nil && request.Relationships.Requestor.Data != nil {
+		requestor = request.Relationships.Requestor.Data.ID
+	}
+
+	return limits.Withdrawal{
+		RequestID:   request.ID,
+		Asset:       asset,
+		Destination: address,
+		Requestor:   requestor,
+		Amount:      uint64(details.Attributes.Amount),
+	}
+}
@@ -5,27 +5,35 @@ import (
 	"crypto/ecdsa"
 	"fmt"
 	"math/big"
-	"strings"
+	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/pkg/errors"
+	"github.com/tokend/erc20-withdraw-svc/internal/audit"
+	"github.com/tokend/erc20-withdraw-svc/internal/broadcast"
+	"github.com/tokend/erc20-withdraw-svc/internal/chainguard"
 	"github.com/tokend/erc20-withdraw-svc/internal/config"
+	"github.com/tokend/erc20-withdraw-svc/internal/conversion"
+	"github.com/tokend/erc20-withdraw-svc/internal/eth"
+	"github.com/tokend/erc20-withdraw-svc/internal/fees"
+	"github.com/tokend/erc20-withdraw-svc/internal/hold"
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/getters"
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/page"
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/query"
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/submit"
+	"github.com/tokend/erc20-withdraw-svc/internal/limits"
+	"github.com/tokend/erc20-withdraw-svc/internal/safe"
+	"github.com/tokend/erc20-withdraw-svc/internal/services/screening"
 	"github.com/tokend/erc20-withdraw-svc/internal/services/watchlist"
+	"github.com/tokend/erc20-withdraw-svc/internal/timelock"
+	"github.com/tokend/erc20-withdraw-svc/internal/token"
 	"gitlab.com/distributed_lab/logan/v3"
 	"gitlab.com/tokend/go/xdrbuild"
 )
 
-const erc20ABI = "[{\"constant\":true,\"inputs\":[],\"name\":\"name\",\"outputs\":[{\"name\":\"\",\"type\":\"string\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"_spender\",\"type\":\"address\"},{\"name\":\"_value\",\"type\":\"uint256\"}],\"name\":\"approve\",\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"totalSupply\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"_from\",\"type\":\"address\"},{\"name\":\"_to\",\"type\":\"address\"},{\"name\":\"_value\",\"type\":\"uint256\"}],\"name\":\"transferFrom\",\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"decimals\",\"outputs\":[{\"name\":\"\",\"type\":\"uint8\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"_owner\",\"type\":\"address\"}],\"name\":\"balanceOf\",\"outputs\":[{\"name\":\"balance\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"symbol\",\"outputs\":[{\"name\":\"\",\"type\":\"string\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"_to\",\"type\":\"address\"},{\"name\":\"_value\",\"type\":\"uint256\"}],\"name\":\"transfer\",\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"_owner\",\"type\":\"address\"},{\"name\":\"_spender\",\"type\":\"address\"}],\"name\":\"allowance\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"payable\":true,\"stateMutability\":\"payable\",\"type\":\"fallback\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"name\":\"owner\",\"type\":\"address\"},{\"indexed\":true,\"name\":\"spender\",\"type\":\"address\"},{\"indexed\":false,\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Approval\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"name\":\"from\",\"type\":\"address\"},{\"indexed\":true,\"name\":\"to\",\"type\":\"address\"},{\"indexed\":false,\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Transfer\",\"type\":\"event\"}]"
-
 type Opts struct {
-	Client ethclient.Client
+	Chain config.Chain
 
 	Submitter submit.Interface
 	Builder   xdrbuild.Builder
@@ -33,71 +41,179 @@ type Opts struct {
 	Streamer  getters.CreateWithdrawRequestHandler
 	Config    config.Config
 	Asset     watchlist.Details
+	Screener  *screening.Service
+	Holds     *hold.Store
+	Auditor   audit.Recorder
+	Limits    *limits.Tracker
+	Timelocks *timelock.Store
+	Assets    getters.AssetGetter
+	Proposals *safe.Store
+	// Private sends transactions through relay of the chain, nil if chain has no relay
+	Private broadcast.Sender
 }
 
 type Service struct {
 	withdrawCfg config.WithdrawConfig
 	transferCfg config.TransferConfig
+	limitsCfg   config.LimitsConfig
+	reviewCfg   config.ReviewConfig
+	timelockCfg config.TimelockConfig
+	feesCfg     config.FeesConfig
 	asset       watchlist.Details
 
 	builder     xdrbuild.Builder
 	withdrawals getters.CreateWithdrawRequestHandler
+	assets      getters.AssetGetter
 	txSubmitter submit.Interface
 	log         *logan.Entry
 
-	key      *ecdsa.PrivateKey
-	contract *bind.BoundContract
-	client   *ethclient.Client
+	screener  *screening.Service
+	holds     *hold.Store
+	auditor   audit.Recorder
+	limits    *limits.Tracker
+	timelocks *timelock.Store
+	proposals *safe.Store
+	rates     fees.RateSource
 
-	decimals uint32
-	chainID  *big.Int
+	key      *ecdsa.PrivateKey
+	standard string
+	mode     string
+	contract token.Contract
+	client   eth.Client
+
+	batchCfg    config.AssetBatch
+	multisend   *token.Multisend
+	batch       []queued
+	batchOpened time.Time
+
+	safeCfg  config.AssetSafe
+	multisig *safe.Contract
+
+	privateCfg config.AssetPrivate
+	private    bool
+	// held are private transactions nodes don't count in pending nonce, nil if chain has no relay
+	held broadcast.NonceHolder
+
+	converter conversion.Converter
+	chain     config.Chain
+	chainID   *big.Int
 }
 
-func New(opts Opts) *Service {
-	chainID, err := opts.Client.ChainID(context.Background())
-	if err != nil {
-		panic(errors.Wrap(err, "failed to get chain id"))
+// New creates oracle of the asset, nil service is returned if withdrawals of the asset can't be sent with its config
+// or chain nodes are on unexpected network. Error is returned if chain is unavailable, so creation can be retried.
+func New(opts Opts) (*Service, error) {
+	chainID, err := chainguard.Check(context.Background(), opts.Chain)
+	switch errors.Cause(err) {
+	case nil:
+	case chainguard.ErrChainIDMismatch, chainguard.ErrGenesisMismatch:
+		opts.Log.WithError(err).Error("node is on unexpected chain, refusing to send withdrawals")
+		return nil, nil
+	default:
+		return nil, errors.Wrap(err, "failed to check chain")
 	}
 
-	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	// transactions are signed by bound contracts, while backend decides where to send them
+	backend := broadcast.NewBackend(opts.Chain.Client, opts.Private)
+	privateCfg, private := opts.Config.PrivateConfig().Assets[opts.Asset.ID]
+	if private && opts.Private == nil {
+		opts.Log.Warn("asset chain has no relay, withdrawals are broadcast publicly")
+		private = false
+	}
+	held, _ := opts.Private.(broadcast.NonceHolder)
+
+	params := opts.Asset.Token()
+	if bridge, ok := opts.Config.BridgeConfig().Assets[opts.Asset.ID]; ok {
+		params.Bridge = &bridge
+	}
+	contract, err := token.New(params, backend)
 	if err != nil {
-		opts.Log.WithError(err).Fatal("failed to parse contract ABI")
+		opts.Log.WithError(err).Error("failed to bind token contract")
+		return nil, nil
 	}
 
-	contract := bind.NewBoundContract(
-		opts.Asset.ERC20.Address,
-		parsed,
-		&opts.Client,
-		&opts.Client,
-		&opts.Client,
-	)
+	var multisend *token.Multisend
+	batchCfg, batched := opts.Config.BatchConfig().Assets[opts.Asset.ID]
+	if batched {
+		if params.Standard != token.StandardERC20 || (params.Mode != "" && params.Mode != token.ModeTransfer) {
+			opts.Log.Error("only erc20 transfers can be batched")
+			return nil, nil
+		}
+		multisend, err = token.NewMultisend(common.HexToAddress(batchCfg.Contract), batchCfg.Method, params.Address, backend)
+		if err != nil {
+			opts.Log.WithError(err).Error("failed to bind multisend contract")
+			return nil, nil
+		}
+	}
+
+	var multisig *safe.Contract
+	safeCfg, withSafe := opts.Config.SafeConfig().Assets[opts.Asset.ID]
+	if withSafe {
+		if params.Standard != token.StandardERC20 || (params.Mode != "" && params.Mode != token.ModeTransfer) {
+			opts.Log.Error("only erc20 transfers can be sent from safe")
+			return nil, nil
+		}
+		multisig, err = safe.NewContract(common.HexToAddress(safeCfg.Address), backend)
+		if err != nil {
+			opts.Log.WithError(err).Error("failed to bind safe contract")
+			return nil, nil
+		}
+	}
 
-	decimals := new(uint8)
-	err = contract.Call(&bind.CallOpts{}, decimals, "decimals")
+	decimals, err := contract.Decimals(context.Background())
 	if err != nil {
-		opts.Log.WithError(err).Error("failed to get decimals of erc20 contract")
-		return nil
+		return nil, errors.Wrap(err, "failed to get decimals of token contract")
 	}
 
-	key, err := crypto.HexToECDSA(opts.Config.TransferConfig().Seed)
+	converter := conversion.New(opts.Asset.Attributes.TrailingDigits, decimals)
+	if !converter.Exact() && opts.Config.WithdrawConfig().Remainder == config.RemainderPolicyExact {
+		opts.Log.WithFields(logan.F{
+			"trailing_digits": opts.Asset.Attributes.TrailingDigits,
+			"decimals":        decimals,
+		}).Error("asset amounts can't be converted to token exactly")
+		return nil, nil
+	}
+
+	key, err := crypto.HexToECDSA(opts.Chain.Transfer.Seed)
 	if err != nil {
 		panic(err)
 	}
 
 	return &Service{
-		client:      &opts.Client,
+		client:      opts.Chain.Client,
 		log:         opts.Log,
+		standard:    params.Standard,
+		mode:        params.Mode,
 		contract:    contract,
 		withdrawCfg: opts.Config.WithdrawConfig(),
-		transferCfg: opts.Config.TransferConfig(),
+		transferCfg: opts.Chain.Transfer,
+		limitsCfg:   opts.Config.LimitsConfig(),
+		reviewCfg:   opts.Config.ReviewConfig(),
+		timelockCfg: opts.Config.TimelockConfig(),
+		feesCfg:     opts.Config.FeesConfig(),
 		txSubmitter: opts.Submitter,
 		builder:     opts.Builder,
 		asset:       opts.Asset,
 		key:         key,
 		withdrawals: opts.Streamer,
-		decimals:    uint32(*decimals),
+		assets:      opts.Assets,
+		screener:    opts.Screener,
+		holds:       opts.Holds,
+		auditor:     opts.Auditor,
+		limits:      opts.Limits,
+		timelocks:   opts.Timelocks,
+		rates:       fees.NewRateSource(opts.Config.FeesConfig().Assets[opts.Asset.ID], opts.Config.Horizon()),
+		converter:   converter,
+		chain:       opts.Chain,
 		chainID:     chainID,
-	}
+		batchCfg:    batchCfg,
+		multisend:   multisend,
+		safeCfg:     safeCfg,
+		multisig:    multisig,
+		proposals:   opts.Proposals,
+		privateCfg:  privateCfg,
+		private:     private,
+		held:        held,
+	}, nil
 }
 
 func (s *Service) prepare() {
@@ -0,0 +1,21 @@
+package oracle
+
+import (
+	"context"
+
+	"github.com/tokend/erc20-withdraw-svc/internal/broadcast"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+// isPrivate returns true if withdrawal has to be submitted through private relay instead of public mempool
+func (s *Service) isPrivate(details *regources.CreateWithdrawRequest) bool {
+	return s.private && details.Attributes.Amount > s.privateCfg.Threshold
+}
+
+// sendContext returns context transaction delivering withdrawals is sent with
+func (s *Service) sendContext(ctx context.Context, private bool) context.Context {
+	if private {
+		return broadcast.Private(ctx)
+	}
+	return ctx
+}
@@ -54,6 +54,18 @@ func (s *Service) approveRequest(
 	return nil
 }
 
+// publishDetails adds external details to request without moving it through review. TokenD has no separate
+// operation for that, so it is an approval which neither adds nor removes tasks: request with pending tasks stays
+// on them. Request without pending tasks would be approved by it, so such request is refused.
+func (s *Service) publishDetails(ctx context.Context, request regources.ReviewableRequest, details map[string]interface{}) error {
+	if request.Attributes.PendingTasks == 0 {
+		return errors.From(errors.New("request has no pending tasks, publishing details would approve it"), logan.F{
+			"request_id": request.ID,
+		})
+	}
+	return s.approveRequest(ctx, request, 0, 0, details)
+}
+
 func (s *Service) permanentReject(
 	ctx context.Context, request regources.ReviewableRequest, reason string,
 ) error {
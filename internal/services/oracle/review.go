@@ -0,0 +1,75 @@
+package oracle
+
+import (
+	"context"
+
+	"github.com/tokend/erc20-withdraw-svc/internal/audit"
+	"github.com/tokend/erc20-withdraw-svc/internal/hold"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+const (
+	auditReviewRequired = "review_required"
+)
+
+// requireReview holds withdrawal above asset review threshold, returns true if request must not proceed
+func (s *Service) requireReview(request regources.ReviewableRequest, details *regources.CreateWithdrawRequest, address string) (bool, error) {
+	threshold, ok := s.reviewCfg.Thresholds[s.asset.ID]
+	if !ok || details.Attributes.Amount <= threshold {
+		return false, nil
+	}
+
+	s.auditor.Record(audit.Event{
+		Type:      auditReviewRequired,
+		RequestID: request.ID,
+		Asset:     s.asset.ID,
+		Details: map[string]interface{}{
+			"address":   address,
+			"amount":    details.Attributes.Amount.String(),
+			"threshold": threshold.String(),
+		},
+	})
+	s.log.WithFields(logan.F{
+		"request_id": request.ID,
+		"amount":     details.Attributes.Amount,
+	}).Info("withdrawal exceeds review threshold, holding request for manual review")
+
+	return true, s.hold(request, details, address, exceedsReviewThreshold)
+}
+
+// processHeld handles request put on hold before, returns true if request must not proceed
+func (s *Service) processHeld(ctx context.Context, request regources.ReviewableRequest, held *hold.Request) (bool, error) {
+	fields := logan.F{
+		"request_id": request.ID,
+		"reviewer":   held.DecidedBy,
+	}
+
+	switch held.State {
+	case hold.StateApproved:
+		s.log.WithFields(fields).Info("held request was approved, proceeding")
+		return false, nil
+	case hold.StateRejected:
+		s.log.WithFields(fields).Info("held request was rejected, rejecting withdraw request")
+		return true, s.permanentReject(ctx, request, rejectedByReviewer)
+	default:
+		s.log.WithFields(fields).Debug("request is held for manual review")
+		return true, nil
+	}
+}
+
+func (s *Service) hold(request regources.ReviewableRequest, details *regources.CreateWithdrawRequest, address, reason string) error {
+	err := s.holds.Hold(hold.Request{
+		ID:      request.ID,
+		Asset:   s.asset.ID,
+		Address: address,
+		Amount:  details.Attributes.Amount.String(),
+		Reason:  reason,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to hold request", logan.F{"request_id": request.ID})
+	}
+
+	return nil
+}
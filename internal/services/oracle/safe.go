@@ -0,0 +1,212 @@
+package oracle
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tokend/erc20-withdraw-svc/internal/audit"
+	"github.com/tokend/erc20-withdraw-svc/internal/safe"
+	"github.com/tokend/erc20-withdraw-svc/internal/token"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+const (
+	auditSafeProposed = "safe_proposed"
+)
+
+// awaitSafe proposes Safe transaction for withdrawal above asset Safe threshold and waits for owners to confirm it.
+// Returns proposal once it can be executed, nil if withdrawal is sent from hot wallet and true if request must not proceed yet.
+func (s *Service) awaitSafe(
+	ctx context.Context, request regources.ReviewableRequest, details *regources.CreateWithdrawRequest, transfer token.Transfer,
+) (*safe.Proposal, bool, error) {
+	if s.multisig == nil || details.Attributes.Amount <= s.safeCfg.Threshold {
+		return nil, false, nil
+	}
+
+	fields := logan.F{"request_id": request.ID}
+	nonce, err := s.multisig.Nonce(ctx)
+	if err != nil {
+		return nil, true, errors.Wrap(err, "failed to get safe nonce", fields)
+	}
+
+	proposal, ok := s.proposals.Get(request.ID)
+	switch {
+	case !ok:
+	case proposal.Nonce < nonce:
+		// nonce was taken by another Safe transaction, confirmations of this one are void
+		s.log.WithFields(fields).WithField("nonce", proposal.Nonce).Warn("safe transaction nonce is used, proposing it again")
+		ok = false
+	case s.proposals.NextNonce(proposal.Safe, nonce) < proposal.Nonce:
+		// proposal below was removed, Safe can't execute this one until the gap is filled
+		s.log.WithFields(fields).WithField("nonce", proposal.Nonce).Warn("safe nonce below is free, proposing safe transaction again")
+		ok = false
+	}
+	if !ok {
+		proposal, err = s.propose(ctx, request, transfer, nonce)
+		if err != nil {
+			return nil, true, errors.Wrap(err, "failed to propose safe transaction", fields)
+		}
+	}
+
+	if !proposal.Published {
+		err := s.publishDetails(ctx, request, map[string]interface{}{
+			"safe_tx_hash": proposal.SafeTxHash,
+		})
+		if err != nil {
+			return nil, true, errors.Wrap(err, "failed to publish safe transaction hash", fields)
+		}
+		if err := s.proposals.Published(request.ID); err != nil {
+			return nil, true, errors.Wrap(err, "failed to mark safe transaction hash as published", fields)
+		}
+	}
+
+	threshold, err := s.multisig.Threshold(ctx)
+	if err != nil {
+		return nil, true, errors.Wrap(err, "failed to get safe threshold", fields)
+	}
+	signatures, err := s.confirmations(ctx, proposal)
+	if err != nil {
+		return nil, true, errors.Wrap(err, "failed to check confirmations", fields)
+	}
+	if uint64(len(signatures)) < threshold {
+		s.log.WithFields(fields).WithFields(logan.F{
+			"confirmations": len(signatures),
+			"threshold":     threshold,
+		}).Debug("safe transaction waits for confirmations")
+		return nil, true, nil
+	}
+	if proposal.Nonce != nonce {
+		s.log.WithFields(fields).Debug("safe transaction waits for previous ones to be executed")
+		return nil, true, nil
+	}
+
+	return proposal, false, nil
+}
+
+func (s *Service) propose(ctx context.Context, request regources.ReviewableRequest, transfer token.Transfer, nonce uint64) (*safe.Proposal, error) {
+	data, err := token.TransferData(transfer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build transfer call")
+	}
+	tx := safe.Tx{
+		To:    s.asset.Token().Address,
+		Data:  data,
+		Nonce: s.proposals.NextNonce(s.multisig.Address().String(), nonce),
+	}
+	hash, err := s.multisig.TransactionHash(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := safe.Sign(hash, s.key)
+	if err != nil {
+		return nil, err
+	}
+	owner := crypto.PubkeyToAddress(s.key.PublicKey)
+
+	proposal, err := s.proposals.Propose(safe.Proposal{
+		ID:         request.ID,
+		Asset:      s.asset.ID,
+		Address:    transfer.To.String(),
+		Amount:     transfer.Amount.String(),
+		Safe:       s.multisig.Address().String(),
+		To:         tx.To.String(),
+		Data:       hexutil.Encode(tx.Data),
+		Nonce:      tx.Nonce,
+		SafeTxHash: hash.String(),
+		ProposedAt: time.Now().UTC(),
+		Signatures: map[string]string{
+			strings.ToLower(owner.String()): hexutil.Encode(signature),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditor.Record(audit.Event{
+		Type:      auditSafeProposed,
+		RequestID: request.ID,
+		Asset:     s.asset.ID,
+		Details: map[string]interface{}{
+			"safe":         proposal.Safe,
+			"safe_tx_hash": proposal.SafeTxHash,
+			"nonce":        proposal.Nonce,
+		},
+	})
+	s.log.WithFields(logan.F{
+		"request_id":   request.ID,
+		"safe_tx_hash": proposal.SafeTxHash,
+	}).Info("safe transaction is proposed")
+
+	return proposal, nil
+}
+
+// confirmations returns signatures of current Safe owners, invalid ones are skipped
+func (s *Service) confirmations(ctx context.Context, proposal *safe.Proposal) (map[common.Address][]byte, error) {
+	hash := common.HexToHash(proposal.SafeTxHash)
+	result := make(map[common.Address][]byte, len(proposal.Signatures))
+	for claimed, raw := range proposal.Signatures {
+		signature, err := hexutil.Decode(raw)
+		if err != nil {
+			s.log.WithField("owner", claimed).Warn("safe confirmation is not a hex string")
+			continue
+		}
+		owner, err := safe.Recover(hash, signature)
+		if err != nil || owner != common.HexToAddress(claimed) {
+			s.log.WithField("owner", claimed).Warn("safe confirmation is not signed by the owner")
+			continue
+		}
+		isOwner, err := s.multisig.IsOwner(ctx, owner)
+		if err != nil {
+			return nil, err
+		}
+		if !isOwner {
+			s.log.WithField("owner", claimed).Warn("safe confirmation is signed by non owner")
+			continue
+		}
+		result[owner] = signature
+	}
+
+	return result, nil
+}
+
+// execSafe sends transaction executing confirmed Safe transaction
+func (s *Service) execSafe(ctx context.Context, proposal *safe.Proposal) (*types.Transaction, error) {
+	signatures, err := s.confirmations(ctx, proposal)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check confirmations")
+	}
+	data, err := hexutil.Decode(proposal.Data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode safe transaction data")
+	}
+
+	opts, err := s.transactOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if s.safeCfg.GasLimit != 0 {
+		opts.GasLimit = s.safeCfg.GasLimit
+	}
+	return s.multisig.Exec(opts, safe.Tx{
+		To:    common.HexToAddress(proposal.To),
+		Data:  data,
+		Nonce: proposal.Nonce,
+	}, safe.Pack(signatures))
+}
+
+// proposedAmount returns amount of tokens Safe transaction sends
+func proposedAmount(proposal *safe.Proposal) (*big.Int, error) {
+	amount, ok := new(big.Int).SetString(proposal.Amount, 10)
+	if !ok {
+		return nil, errors.New("invalid proposed amount")
+	}
+	return amount, nil
+}
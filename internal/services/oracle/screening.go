@@ -0,0 +1,50 @@
+package oracle
+
+import (
+	"context"
+
+	"github.com/tokend/erc20-withdraw-svc/internal/audit"
+	"github.com/tokend/erc20-withdraw-svc/internal/config"
+	"gitlab.com/distributed_lab/logan/v3"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+const (
+	auditScreeningHit = "screening_hit"
+)
+
+// screen checks destination address against deny lists, returns true if request must not proceed
+func (s *Service) screen(
+	ctx context.Context, request regources.ReviewableRequest, details *regources.CreateWithdrawRequest, address string,
+) (bool, error) {
+	entry := s.screener.Screen(address)
+	if entry == nil {
+		return false, nil
+	}
+
+	fields := logan.F{
+		"request_id": request.ID,
+		"address":    address,
+		"list":       entry.List,
+	}
+	policy := s.screener.Policy()
+	s.auditor.Record(audit.Event{
+		Type:      auditScreeningHit,
+		RequestID: request.ID,
+		Asset:     s.asset.ID,
+		Details: map[string]interface{}{
+			"address": address,
+			"list":    entry.List,
+			"reason":  entry.Reason,
+			"policy":  policy,
+		},
+	})
+
+	if policy == config.ScreeningPolicyReject {
+		s.log.WithFields(fields).Warn("destination address is denied, rejecting request")
+		return true, s.permanentReject(ctx, request, deniedAddress)
+	}
+
+	s.log.WithFields(fields).Warn("destination address is denied, holding request for manual review")
+	return true, s.hold(request, details, address, deniedAddress)
+}
@@ -16,15 +16,16 @@ func (s *Service) Run(ctx context.Context) {
 
 	running.WithBackOff(ctx, s.log, "sender", func(ctx context.Context) error {
 		if len(withdrawPage.Data) < requestPageSizeLimit {
-			withdrawPage, err = s.withdrawals.List()
+			withdrawPage, err = s.withdrawals.ListContext(ctx)
 		} else {
-			withdrawPage, err = s.withdrawals.Next()
+			withdrawPage, err = s.withdrawals.NextContext(ctx)
 		}
 		if err != nil {
 			return errors.Wrap(err, "error occurred while withdrawal request page fetching")
 		}
 		if len(withdrawPage.Data) == 0 {
 			s.log.WithField("asset", s.asset.ID).Debug("no pending withdraw requests")
+			s.flushBatch(ctx)
 			return nil
 		} 
 		for _, data := range withdrawPage.Data {
@@ -37,6 +38,7 @@ func (s *Service) Run(ctx context.Context) {
 					Warn("failed to process withdraw request")
 			}
 		}
+		s.flushBatch(ctx)
 		return nil
 	}, 15*time.Second, 15*time.Second, time.Hour)
 }
@@ -0,0 +1,46 @@
+package oracle
+
+import (
+	"context"
+
+	"github.com/tokend/erc20-withdraw-svc/internal/token"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+var errSupplyExceeded = errors.New("on-chain supply and TokenD issued amount exceed max issuance")
+
+// checkSupply ensures tokens minted on chain together with ones circulating in TokenD don't exceed
+// max issuance of the asset, so minting never creates tokens not backed by TokenD
+func (s *Service) checkSupply(ctx context.Context) error {
+	supplier, ok := s.contract.(token.Supplier)
+	if !ok || s.mode != token.ModeMint {
+		return nil
+	}
+
+	asset, err := s.assets.ByIDContext(ctx, s.asset.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get asset")
+	}
+	supply, err := supplier.TotalSupply(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get total supply")
+	}
+	// dust below TokenD precision can't break the invariant, so it is ignored
+	chainSupply, _, err := s.converter.FromToken(supply)
+	if err != nil {
+		return errors.Wrap(err, "failed to convert total supply")
+	}
+
+	issued := uint64(asset.Data.Attributes.Issued)
+	maxIssuance := uint64(asset.Data.Attributes.MaxIssuanceAmount)
+	if chainSupply > maxIssuance || issued > maxIssuance-chainSupply {
+		return errors.From(errSupplyExceeded, logan.F{
+			"chain_supply": chainSupply,
+			"issued":       issued,
+			"max_issuance": maxIssuance,
+		})
+	}
+
+	return nil
+}
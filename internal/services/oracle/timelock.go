@@ -0,0 +1,98 @@
+package oracle
+
+import (
+	"context"
+	"time"
+
+	"github.com/tokend/erc20-withdraw-svc/internal/audit"
+	"github.com/tokend/erc20-withdraw-svc/internal/timelock"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+const (
+	auditTimelockScheduled = "timelock_scheduled"
+	auditTimelockReleased  = "timelock_released"
+)
+
+// awaitTimelock delays withdrawal above asset time lock threshold, returns true if request must not proceed yet
+func (s *Service) awaitTimelock(ctx context.Context, request regources.ReviewableRequest, details *regources.CreateWithdrawRequest, address string) (bool, error) {
+	cfg, ok := s.timelockCfg.Assets[s.asset.ID]
+	if !ok || details.Attributes.Amount <= cfg.Threshold {
+		return false, nil
+	}
+
+	fields := logan.F{"request_id": request.ID}
+	lock, ok := s.timelocks.Get(request.ID)
+	if !ok {
+		now := time.Now().UTC()
+		var err error
+		lock, err = s.timelocks.Lock(timelock.Lock{
+			ID:       request.ID,
+			Asset:    s.asset.ID,
+			Address:  address,
+			Amount:   details.Attributes.Amount.String(),
+			LockedAt: now,
+			SendAt:   now.Add(cfg.Delay),
+		})
+		if err != nil {
+			return true, errors.Wrap(err, "failed to lock request", fields)
+		}
+
+		s.auditor.Record(audit.Event{
+			Type:      auditTimelockScheduled,
+			RequestID: request.ID,
+			Asset:     s.asset.ID,
+			Details: map[string]interface{}{
+				"address": address,
+				"amount":  lock.Amount,
+				"send_at": lock.SendAt,
+			},
+		})
+		s.log.WithFields(fields).WithField("send_at", lock.SendAt).Info("withdrawal is time locked")
+	}
+
+	if !lock.Published && lock.State == timelock.StateLocked {
+		err := s.publishDetails(ctx, request, map[string]interface{}{
+			"scheduled_send_at": lock.SendAt.Format(time.RFC3339),
+		})
+		if err != nil {
+			return true, errors.Wrap(err, "failed to publish scheduled send time", fields)
+		}
+		if err := s.timelocks.Published(request.ID); err != nil {
+			return true, errors.Wrap(err, "failed to mark send time as published", fields)
+		}
+	}
+
+	switch lock.State {
+	case timelock.StateCancelled:
+		s.log.WithFields(fields).WithField("cancelled_by", lock.CancelledBy).Info("time locked request was cancelled, rejecting withdraw request")
+		return true, s.permanentReject(ctx, request, timelockCancelled)
+	case timelock.StateReleased:
+		return false, nil
+	}
+
+	if time.Now().Before(lock.SendAt) {
+		s.log.WithFields(fields).Debug("request is time locked")
+		return true, nil
+	}
+
+	switch err := s.timelocks.Release(request.ID); err {
+	case nil:
+	case timelock.ErrCancelled:
+		// cancelled right before release, rejecting on the next iteration
+		return true, nil
+	default:
+		return true, errors.Wrap(err, "failed to release time lock", fields)
+	}
+
+	s.auditor.Record(audit.Event{
+		Type:      auditTimelockReleased,
+		RequestID: request.ID,
+		Asset:     s.asset.ID,
+	})
+	s.log.WithFields(fields).Info("time lock expired, proceeding")
+
+	return false, nil
+}
@@ -0,0 +1,109 @@
+package screening
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// Entry is a single deny list record
+type Entry struct {
+	Address string `json:"address"`
+	Reason  string `json:"reason"`
+	List    string `json:"-"`
+}
+
+func parseList(path string, raw []byte) (map[string]Entry, error) {
+	var entries []Entry
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		entries, err = parseCSV(raw)
+	case ".json":
+		entries, err = parseJSON(raw)
+	default:
+		return nil, errors.From(errors.New("unsupported deny list format"), logan.F{"path": path})
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse deny list", logan.F{"path": path})
+	}
+
+	result := make(map[string]Entry, len(entries))
+	for i, entry := range entries {
+		address := strings.TrimSpace(entry.Address)
+		if !common.IsHexAddress(address) {
+			return nil, errors.From(errors.New("invalid address in deny list"), logan.F{
+				"path":    path,
+				"entry":   i,
+				"address": entry.Address,
+			})
+		}
+		entry.Address = normalize(address)
+		entry.List = filepath.Base(path)
+		result[entry.Address] = entry
+	}
+
+	return result, nil
+}
+
+// parseCSV expects address in the first column and optional reason in the second one,
+// header line is skipped if present
+func parseCSV(raw []byte) ([]Entry, error) {
+	reader := csv.NewReader(bytes.NewReader(raw))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	var result []Entry
+	for line := 0; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read csv record")
+		}
+		if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if line == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "address") {
+			continue
+		}
+
+		entry := Entry{Address: record[0]}
+		if len(record) > 1 {
+			entry.Reason = strings.TrimSpace(record[1])
+		}
+		result = append(result, entry)
+	}
+}
+
+// parseJSON accepts either array of addresses or array of entry objects
+func parseJSON(raw []byte) ([]Entry, error) {
+	var addresses []string
+	if err := json.Unmarshal(raw, &addresses); err == nil {
+		result := make([]Entry, 0, len(addresses))
+		for _, address := range addresses {
+			result = append(result, Entry{Address: address})
+		}
+		return result, nil
+	}
+
+	var result []Entry
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal json list")
+	}
+
+	return result, nil
+}
+
+func normalize(address string) string {
+	return strings.ToLower(common.HexToAddress(address).Hex())
+}
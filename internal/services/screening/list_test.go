@@ -0,0 +1,43 @@
+package screening
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseList(t *testing.T) {
+	const address = "0x8576acc5c05d6ce88f4e49bf65bdf0c62f91353c"
+
+	t.Run("csv with header", func(t *testing.T) {
+		raw := []byte("address,reason\n0x8576ACC5C05D6CE88F4E49BF65BDF0C62F91353C, sanctioned\n")
+		entries, err := parseList("/lists/ofac.csv", raw)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]Entry{
+			address: {Address: address, Reason: "sanctioned", List: "ofac.csv"},
+		}, entries)
+	})
+
+	t.Run("json addresses", func(t *testing.T) {
+		entries, err := parseList("internal.json", []byte(`["0x8576acc5c05d6ce88f4e49bf65bdf0c62f91353c"]`))
+		assert.NoError(t, err)
+		assert.Contains(t, entries, address)
+	})
+
+	t.Run("json entries", func(t *testing.T) {
+		raw := []byte(`[{"address": "0x8576acc5c05d6ce88f4e49bf65bdf0c62f91353c", "reason": "fraud"}]`)
+		entries, err := parseList("internal.json", raw)
+		assert.NoError(t, err)
+		assert.Equal(t, "fraud", entries[address].Reason)
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		_, err := parseList("ofac.csv", []byte("not-an-address\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := parseList("ofac.txt", []byte(address))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,78 @@
+package screening
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tokend/erc20-withdraw-svc/internal/config"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// Service is struct representing destination address screening service
+type Service struct {
+	log          *logan.Entry
+	policy       string
+	reloadPeriod time.Duration
+
+	mu       sync.RWMutex
+	lists    map[string]map[string]Entry
+	modTimes map[string]time.Time
+}
+
+// Opts contain parameters required to build service
+type Opts struct {
+	Log    *logan.Entry
+	Config config.ScreeningConfig
+}
+
+// New creates new screening service, panics if any of deny lists can't be loaded
+func New(opts Opts) *Service {
+	s := &Service{
+		log:          opts.Log.WithField("service", "screening"),
+		policy:       opts.Config.Policy,
+		reloadPeriod: opts.Config.ReloadPeriod,
+		lists:        make(map[string]map[string]Entry),
+		modTimes:     make(map[string]time.Time),
+	}
+
+	for _, path := range opts.Config.Lists {
+		if err := s.load(path); err != nil {
+			panic(errors.Wrap(err, "failed to load deny list"))
+		}
+	}
+
+	return s
+}
+
+// Policy returns action to be taken on deny list hit
+func (s *Service) Policy() string {
+	return s.policy
+}
+
+func (s *Service) load(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to stat deny list", logan.F{"path": path})
+	}
+
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to read deny list", logan.F{"path": path})
+	}
+
+	entries, err := parseList(path, raw)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.lists[path] = entries
+	s.modTimes[path] = info.ModTime()
+	s.mu.Unlock()
+
+	s.log.WithFields(logan.F{"path": path, "entries": len(entries)}).Info("deny list loaded")
+	return nil
+}
@@ -0,0 +1,61 @@
+package screening
+
+import (
+	"context"
+	"os"
+
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	"gitlab.com/distributed_lab/running"
+)
+
+// Run reloads deny lists once their files change
+func (s *Service) Run(ctx context.Context) {
+	if len(s.modTimes) == 0 {
+		return
+	}
+
+	running.WithBackOff(ctx, s.log, "deny-list-reloader", s.reloadChanged, s.reloadPeriod, s.reloadPeriod, 10*s.reloadPeriod)
+}
+
+// Screen returns deny list entry matching address or nil if address is not listed
+func (s *Service) Screen(address string) *Entry {
+	normalized := normalize(address)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, list := range s.lists {
+		if entry, ok := list[normalized]; ok {
+			return &entry
+		}
+	}
+
+	return nil
+}
+
+// reloadChanged reloads lists modified since last load. List which fails to load is kept in its
+// previous state, so broken update never lifts screening.
+func (s *Service) reloadChanged(ctx context.Context) error {
+	s.mu.RLock()
+	changed := make([]string, 0, len(s.modTimes))
+	for path, modTime := range s.modTimes {
+		info, err := os.Stat(path)
+		if err != nil {
+			s.mu.RUnlock()
+			return errors.Wrap(err, "failed to stat deny list", logan.F{"path": path})
+		}
+		if !info.ModTime().Equal(modTime) {
+			changed = append(changed, path)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, path := range changed {
+		if err := s.load(path); err != nil {
+			return errors.Wrap(err, "failed to reload deny list, keeping previous version")
+		}
+	}
+
+	return nil
+}
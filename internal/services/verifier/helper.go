@@ -6,11 +6,11 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/tokend/erc20-withdraw-svc/internal/token"
 	"gitlab.com/distributed_lab/logan/v3"
 	"gitlab.com/distributed_lab/logan/v3/errors"
 	regources "gitlab.com/tokend/regources/generated"
 	"math/big"
-	"strings"
 )
 
 const (
@@ -29,23 +29,22 @@ const (
 	transferFailed = "Transfer failed"
 )
 
-type ERC20Transfer struct {
-	From  common.Address
-	To    common.Address
-	Value *big.Int
-	Raw   types.Log
-}
-
 type SentDetails struct {
 	Amount    string `json:"amount"`
 	EthTxHash string `json:"eth_tx_hash"`
+	// RequestRef is set if withdrawal was sent through bridge contract
+	RequestRef string `json:"request_ref"`
+	// BatchPosition is a position of withdrawal in multisend call, nil if it was sent alone
+	BatchPosition *int `json:"batch_position"`
+	// SafeTxHash is set if withdrawal was sent from Safe
+	SafeTxHash string `json:"safe_tx_hash"`
 }
 
 type ExternalDetails struct {
 	Data []json.RawMessage `json:"data"`
 }
 
-func (s *Service) confirmWithdrawSuccessful(ctx context.Context, request regources.ReviewableRequest, details *regources.CreateWithdrawRequest) error {
+func (s *Service) confirmWithdrawSuccessful(ctx context.Context, request regources.ReviewableRequest, details *regources.CreateWithdrawRequest, head uint64) error {
 	fields := logan.F{
 		"request_id": request.ID,
 		"amount":     details.Attributes.Amount,
@@ -68,7 +67,7 @@ func (s *Service) confirmWithdrawSuccessful(ctx context.Context, request regourc
 		return s.permanentReject(ctx, request, invalidTXHash)
 	}
 	fields["eth_tx_hash"] = withdrawDetails.EthTxHash
-	receipt, err := s.client.TransactionReceipt(ctx, common.HexToHash(withdrawDetails.EthTxHash))
+	receipt, err := s.lookups.Receipt(ctx, common.HexToHash(withdrawDetails.EthTxHash))
 	if err == ethereum.NotFound {
 		s.log.WithFields(fields).Debug("transaction receipt not found")
 		return nil
@@ -82,19 +81,51 @@ func (s *Service) confirmWithdrawSuccessful(ctx context.Context, request regourc
 		return s.permanentReject(ctx, request, txFailed)
 	}
 
-	if !s.LogsSuccessful(receipt, getAddress(details.Attributes.CreatorDetails), withdrawDetails.Amount) {
+	transfer, err := expectedTransfer(details.Attributes.CreatorDetails, withdrawDetails)
+	if err != nil {
+		return errors.Wrap(err, "failed to build expected transfer", fields)
+	}
+	if withdrawDetails.SafeTxHash != "" {
+		if s.multisig == nil {
+			return errors.From(errors.New("withdrawal was sent from safe, but safe is not configured"), fields)
+		}
+		// Transfer event alone does not tell whether it is a result of the expected Safe transaction
+		if !s.multisig.Executed(receipt.Logs, common.HexToHash(withdrawDetails.SafeTxHash)) {
+			s.log.WithFields(fields).WithField("safe_tx_hash", withdrawDetails.SafeTxHash).Warn("Safe transaction was not executed...")
+			return errors.From(errors.New("safe transaction was not executed"), fields)
+		}
+	}
+
+	finalDetails := map[string]interface{}{
+		"eth_block_number": receipt.BlockNumber.Int64(),
+		"gas_used":         receipt.GasUsed,
+	}
+	if position := withdrawDetails.BatchPosition; position != nil {
+		if s.multisend == nil {
+			return errors.From(errors.New("withdrawal was sent in batch, but batching is not configured"), fields)
+		}
+		logIndex, ok := s.multisend.Delivered(receipt.Logs, *position, transfer)
+		if !ok {
+			s.log.WithFields(fields).WithField("batch_position", *position).Warn("Transfer unsuccessful...")
+			return errors.From(errors.New("transfer unsuccessful"), fields)
+		}
+		finalDetails["eth_log_index"] = logIndex
+	} else if !s.LogsSuccessful(receipt, transfer) {
 		s.log.WithFields(fields).Warn("Transfer unsuccessful...")
 		return errors.From(errors.New("transfer unsuccessful"), fields)
 	}
 
-	if !s.ensureEnoughConfirmations(ctx, receipt.BlockNumber.Int64()) {
+	if !s.ensureEnoughConfirmations(ctx, receipt, head) {
 		s.log.WithFields(fields).Debug("waiting for confirmations")
+		s.inFlight[request.ID] = inFlight{
+			request: request,
+			details: details,
+			due:     receipt.BlockNumber.Uint64() + uint64(s.ethCfg.Confirmations),
+		}
 		return nil
 	}
 
-	err = s.approveRequest(ctx, request, 0, taskCheckTxConfirmed, map[string]interface{}{
-		"eth_block_number": receipt.BlockNumber.Int64(),
-	})
+	err = s.approveRequest(ctx, request, 0, taskCheckTxConfirmed, finalDetails)
 	if err != nil {
 		return errors.Wrap(err, "failed to review request second time", fields)
 	}
@@ -103,13 +134,36 @@ func (s *Service) confirmWithdrawSuccessful(ctx context.Context, request regourc
 	return nil
 }
 
-func (s *Service) ensureEnoughConfirmations(ctx context.Context, blockNumber int64) bool {
-	_, err := s.client.BlockByNumber(ctx, big.NewInt(blockNumber+s.ethCfg.Confirmations))
-	if err == ethereum.NotFound {
+func (s *Service) ensureEnoughConfirmations(ctx context.Context, receipt *types.Receipt, head uint64) bool {
+	blockNumber := receipt.BlockNumber.Int64()
+	if uint64(blockNumber+s.ethCfg.Confirmations) > head {
+		return false
+	}
+	if s.chain.Quorum == 0 {
+		return true
+	}
+
+	// block of transaction must still be canonical on quorum of nodes, and confirmed on them
+	header, err := s.lookups.Header(ctx, receipt.BlockNumber)
+	if err != nil {
+		if err != ethereum.NotFound {
+			s.log.WithError(err).Error("got error trying to fetch block")
+		}
 		return false
 	}
+	if header.Hash() != receipt.BlockHash {
+		s.log.WithFields(logan.F{
+			"block_number": blockNumber,
+			"block_hash":   receipt.BlockHash.Hex(),
+		}).Warn("transaction block is not canonical on quorum of nodes")
+		return false
+	}
+
+	_, err = s.lookups.Header(ctx, big.NewInt(blockNumber+s.ethCfg.Confirmations))
 	if err != nil {
-		s.log.WithError(err).Error("got error trying to fetch block")
+		if err != ethereum.NotFound {
+			s.log.WithError(err).Error("got error trying to fetch block")
+		}
 		return false
 	}
 
@@ -127,34 +181,43 @@ func (s *Service) getWithdrawDetails(ext ExternalDetails) SentDetails {
 	return SentDetails{}
 }
 
-func (s *Service) LogsSuccessful(receipt *types.Receipt, destination string, amount string) bool {
+func (s *Service) LogsSuccessful(receipt *types.Receipt, transfer token.Transfer) bool {
 	for _, log := range receipt.Logs {
 		if log.Removed {
 			return false
 		}
-		parsed := new(ERC20Transfer)
-		if err := s.contract.UnpackLog(parsed, "Transfer", *log); err != nil {
-			continue
-		}
-		if strings.ToLower(parsed.To.String()) != strings.ToLower(destination) {
-			continue
+		if s.contract.Delivered(*log, transfer) {
+			return true
 		}
-
-		if parsed.Value.String() != amount {
-			continue
-		}
-
-		return true
 	}
 
 	return false
 }
 
-func getAddress(details []byte) string {
+// expectedTransfer builds transfer withdrawal transaction must contain from request details
+func expectedTransfer(creatorDetails []byte, sent SentDetails) (token.Transfer, error) {
 	withdrawDetails := struct {
 		Address string `json:"address"`
+		TokenID string `json:"token_id"`
 	}{}
-	json.Unmarshal(details, &withdrawDetails)
+	json.Unmarshal(creatorDetails, &withdrawDetails)
+
+	amount, ok := new(big.Int).SetString(sent.Amount, 10)
+	if !ok {
+		return token.Transfer{}, errors.From(errors.New("invalid sent amount"), logan.F{"amount": sent.Amount})
+	}
+	transfer := token.Transfer{
+		To:     common.HexToAddress(withdrawDetails.Address),
+		Amount: amount,
+		Ref:    common.HexToHash(sent.RequestRef),
+	}
+	if withdrawDetails.TokenID != "" {
+		tokenID, ok := new(big.Int).SetString(withdrawDetails.TokenID, 10)
+		if !ok {
+			return token.Transfer{}, errors.From(errors.New("invalid token id"), logan.F{"token_id": withdrawDetails.TokenID})
+		}
+		transfer.TokenID = tokenID
+	}
 
-	return withdrawDetails.Address
+	return transfer, nil
 }
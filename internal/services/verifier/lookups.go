@@ -0,0 +1,29 @@
+package verifier
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/tokend/erc20-withdraw-svc/internal/eth"
+)
+
+// Lookups returns receipts and headers verification relies on, ethereum.NotFound if chain does not have them yet
+type Lookups interface {
+	Receipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	Header(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// direct looks up receipts and headers one by one
+type direct struct {
+	client eth.Client
+}
+
+func (d direct) Receipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return d.client.TransactionReceipt(ctx, txHash)
+}
+
+func (d direct) Header(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return d.client.HeaderByNumber(ctx, number)
+}
@@ -1,22 +1,26 @@
 package verifier
 
 import (
-	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/tokend/erc20-withdraw-svc/internal/config"
+	"github.com/tokend/erc20-withdraw-svc/internal/eth"
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/getters"
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/submit"
+	"github.com/tokend/erc20-withdraw-svc/internal/safe"
 	"github.com/tokend/erc20-withdraw-svc/internal/services/watchlist"
+	"github.com/tokend/erc20-withdraw-svc/internal/token"
 	"gitlab.com/distributed_lab/logan/v3"
 	"gitlab.com/tokend/go/xdrbuild"
-	"strings"
+	regources "gitlab.com/tokend/regources/generated"
 )
 
-const erc20ABI = "[{\"constant\":true,\"inputs\":[],\"name\":\"name\",\"outputs\":[{\"name\":\"\",\"type\":\"string\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"_spender\",\"type\":\"address\"},{\"name\":\"_value\",\"type\":\"uint256\"}],\"name\":\"approve\",\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"totalSupply\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"_from\",\"type\":\"address\"},{\"name\":\"_to\",\"type\":\"address\"},{\"name\":\"_value\",\"type\":\"uint256\"}],\"name\":\"transferFrom\",\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"decimals\",\"outputs\":[{\"name\":\"\",\"type\":\"uint8\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"_owner\",\"type\":\"address\"}],\"name\":\"balanceOf\",\"outputs\":[{\"name\":\"balance\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"symbol\",\"outputs\":[{\"name\":\"\",\"type\":\"string\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"_to\",\"type\":\"address\"},{\"name\":\"_value\",\"type\":\"uint256\"}],\"name\":\"transfer\",\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"_owner\",\"type\":\"address\"},{\"name\":\"_spender\",\"type\":\"address\"}],\"name\":\"allowance\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"payable\":true,\"stateMutability\":\"payable\",\"type\":\"fallback\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"name\":\"owner\",\"type\":\"address\"},{\"indexed\":true,\"name\":\"spender\",\"type\":\"address\"},{\"indexed\":false,\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Approval\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"name\":\"from\",\"type\":\"address\"},{\"indexed\":true,\"name\":\"to\",\"type\":\"address\"},{\"indexed\":false,\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Transfer\",\"type\":\"event\"}]"
-
 type Opts struct {
-	Client ethclient.Client
+	Chain config.Chain
+	// Lookups are shared with verifiers of other assets on the chain, receipts and headers are requested
+	// from chain client directly if not set
+	Lookups Lookups
 
 	Submitter submit.Interface
 	Builder   xdrbuild.Builder
@@ -36,35 +40,66 @@ type Service struct {
 	txSubmitter submit.Interface
 	log         *logan.Entry
 
-	client *ethclient.Client
+	client  eth.Client
+	chain   config.Chain
+	lookups Lookups
+
+	contract  token.Contract
+	multisend *token.Multisend
+	multisig  *safe.Contract
 
-	contract *bind.BoundContract
+	withdrawPage *regources.ReviewableRequestListResponse
+	// listed is a time page of requests was last requested
+	listed time.Time
+	// inFlight are requests waiting for confirmations by id, they are re-checked once confirmed
+	inFlight map[string]inFlight
 }
 
 func New(opts Opts) *Service {
 
-	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	params := opts.Asset.Token()
+	if bridge, ok := opts.Config.BridgeConfig().Assets[opts.Asset.ID]; ok {
+		params.Bridge = &bridge
+	}
+	contract, err := token.New(params, opts.Chain.Client)
 	if err != nil {
-		opts.Log.WithError(err).Fatal("failed to parse contract ABI")
+		opts.Log.WithError(err).Fatal("failed to bind token contract")
 	}
 
-	contract := bind.NewBoundContract(
-		opts.Asset.ERC20.Address,
-		parsed,
-		&opts.Client,
-		&opts.Client,
-		&opts.Client,
-	)
+	var multisend *token.Multisend
+	if batch, ok := opts.Config.BatchConfig().Assets[opts.Asset.ID]; ok {
+		multisend, err = token.NewMultisend(common.HexToAddress(batch.Contract), batch.Method, params.Address, opts.Chain.Client)
+		if err != nil {
+			opts.Log.WithError(err).Fatal("failed to bind multisend contract")
+		}
+	}
+
+	var multisig *safe.Contract
+	if safeCfg, ok := opts.Config.SafeConfig().Assets[opts.Asset.ID]; ok {
+		multisig, err = safe.NewContract(common.HexToAddress(safeCfg.Address), opts.Chain.Client)
+		if err != nil {
+			opts.Log.WithError(err).Fatal("failed to bind safe contract")
+		}
+	}
+
+	var lookups Lookups = direct{client: opts.Chain.Client}
+	if opts.Lookups != nil {
+		lookups = opts.Lookups
+	}
 
 	return &Service{
-		client:      &opts.Client,
+		client:      opts.Chain.Client,
+		lookups:     lookups,
+		chain:       opts.Chain,
 		log:         opts.Log,
 		withdrawCfg: opts.Config.WithdrawConfig(),
-		ethCfg:      opts.Config.TransferConfig(),
+		ethCfg:      opts.Chain.Transfer,
 		txSubmitter: opts.Submitter,
 		builder:     opts.Builder,
 		asset:       opts.Asset,
 		contract:    contract,
+		multisend:   multisend,
+		multisig:    multisig,
 
 		withdrawals: opts.Streamer,
 	}
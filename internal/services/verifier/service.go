@@ -3,44 +3,140 @@ package verifier
 import (
 	"context"
 	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/page"
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/query"
+	"gitlab.com/distributed_lab/logan/v3"
 	"gitlab.com/distributed_lab/logan/v3/errors"
 	"gitlab.com/distributed_lab/running"
 	regources "gitlab.com/tokend/regources/generated"
-	"time"
 )
 
+const (
+	// resubscribePeriod is how long verifier polls after subscription failure before subscribing again
+	resubscribePeriod = time.Minute
+)
+
+// inFlight is a request which transaction is mined, but not confirmed yet
+type inFlight struct {
+	request regources.ReviewableRequest
+	details *regources.CreateWithdrawRequest
+	// due is a block number request has enough confirmations at
+	due uint64
+}
+
+// Run verifies requests on every new head, falling back to polling if node can't notify about new heads
 func (s *Service) Run(ctx context.Context) {
 	s.prepare()
-	withdrawPage := &regources.ReviewableRequestListResponse{}
-	var err error
-	running.WithBackOff(ctx, s.log, "verifier", func(ctx context.Context) error {
-		if len(withdrawPage.Data) < requestPageSizeLimit {
-			withdrawPage, err = s.withdrawals.List()
-		} else {
-			withdrawPage, err = s.withdrawals.Next()
-		}
+	for ctx.Err() == nil {
+		heads := make(chan *types.Header)
+		sub, err := s.client.SubscribeNewHead(ctx, heads)
 		if err != nil {
-			return errors.Wrap(err, "error occurred while withdrawal request page fetching")
+			s.log.WithError(err).Warn("failed to subscribe to new heads, falling back to polling")
+			s.poll(ctx)
+			continue
 		}
-		for _, data := range withdrawPage.Data {
-			details := withdrawPage.Included.MustCreateWithdrawRequest(data.Relationships.RequestDetails.Data.GetKey())
-			err := s.confirmWithdrawSuccessful(ctx, data, details)
-			if err != nil {
-				s.log.
-					WithError(err).
-					WithField("details", details).
-					Warn("failed to process withdraw request")
-				continue
+
+		s.follow(ctx, sub, heads)
+	}
+}
+
+// follow verifies requests on every head until subscription drops
+func (s *Service) follow(ctx context.Context, sub ethereum.Subscription, heads chan *types.Header) {
+	defer sub.Unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-sub.Err():
+			s.log.WithError(err).Warn("new heads subscription dropped, falling back to polling")
+			s.poll(ctx)
+			return
+		case head := <-heads:
+			// verification may be slower than blocks, there is no point to process heads we are already behind
+			for drained := false; !drained; {
+				select {
+				case head = <-heads:
+				default:
+					drained = true
+				}
+			}
+			if err := s.iterate(ctx, head.Number.Uint64()); err != nil {
+				s.log.WithError(err).WithField("head", head.Number).Error("failed to verify requests")
 			}
 		}
+	}
+}
+
+// poll verifies requests periodically for resubscribePeriod
+func (s *Service) poll(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, resubscribePeriod)
+	defer cancel()
+	running.WithBackOff(ctx, s.log, "verifier", func(ctx context.Context) error {
+		head, err := s.client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return errors.Wrap(err, "failed to get head")
+		}
+		return s.iterate(ctx, head.Number.Uint64())
+	}, s.withdrawCfg.PollingPeriod, s.withdrawCfg.PollingPeriod, time.Hour)
+}
+
+// iterate re-checks in-flight requests which are confirmed at head. Next page of requests is processed
+// only once polling period is over, so new heads don't multiply requests to Horizon.
+func (s *Service) iterate(ctx context.Context, head uint64) error {
+	for id, item := range s.inFlight {
+		if item.due > head {
+			continue
+		}
+		// request is tracked again if it still needs confirmations, e.g. after reorg
+		delete(s.inFlight, id)
+		s.confirm(ctx, item.request, item.details, head)
+	}
+
+	if time.Since(s.listed) < s.withdrawCfg.PollingPeriod {
 		return nil
-	}, 15*time.Second, 15*time.Second, time.Hour)
+	}
+	s.listed = time.Now()
+
+	var err error
+	if len(s.withdrawPage.Data) < requestPageSizeLimit {
+		s.withdrawPage, err = s.withdrawals.ListContext(ctx)
+	} else {
+		s.withdrawPage, err = s.withdrawals.NextContext(ctx)
+	}
+	if err != nil {
+		return errors.Wrap(err, "error occurred while withdrawal request page fetching")
+	}
+	for _, data := range s.withdrawPage.Data {
+		if _, ok := s.inFlight[data.ID]; ok {
+			continue
+		}
+		details := s.withdrawPage.Included.MustCreateWithdrawRequest(data.Relationships.RequestDetails.Data.GetKey())
+		s.confirm(ctx, data, details, head)
+	}
+	return nil
 }
 
+func (s *Service) confirm(ctx context.Context, request regources.ReviewableRequest, details *regources.CreateWithdrawRequest, head uint64) {
+	err := s.confirmWithdrawSuccessful(ctx, request, details, head)
+	if err != nil {
+		s.log.
+			WithError(err).
+			WithFields(logan.F{
+				"request_id": request.ID,
+				"details":    details,
+			}).
+			Warn("failed to process withdraw request")
+	}
+}
 
 func (s *Service) prepare() {
+	s.withdrawPage = &regources.ReviewableRequestListResponse{}
+	s.inFlight = make(map[string]inFlight)
+
 	state := reviewableRequestStatePending
 	pendingTasks := fmt.Sprintf("%d", taskCheckTxConfirmed)
 	pendingTasksNotSet := fmt.Sprintf("%d", (taskTryTransfer | taskCheckTxSentSuccess))
@@ -62,4 +158,4 @@ func (s *Service) prepare() {
 	})
 	limit := fmt.Sprintf("%d", requestPageSizeLimit)
 	s.withdrawals.SetPageParams(page.Params{Limit: &limit})
-}
\ No newline at end of file
+}
@@ -1,26 +1,80 @@
 package watchlist
 
 import (
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common"
 	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/tokend/erc20-withdraw-svc/internal/token"
 	regources "gitlab.com/tokend/regources/generated"
 )
 
+//ContractDetails describe token contract withdrawals are sent to
+type ContractDetails struct {
+	Withdraw bool           `json:"withdraw"`
+	Address  common.Address `json:"address"`
+	// Chain is a name of configured chain token lives on, default one if empty
+	Chain string `json:"chain"`
+	// TokenID is an id of the asset within ERC-1155 contract
+	TokenID string `json:"token_id"`
+	// Mode is either `transfer`, `mint` or `bridge`, ERC20 only
+	Mode string `json:"mode"`
+	// MintMethod is a name of contract method used in `mint` mode
+	MintMethod string `json:"mint_method"`
+}
+
 //AssetDetails contain details about asset that can be deposited using service
 type AssetDetails struct {
-	ExternalSystemType int32 `json:"external_system_type,string"`
-	ERC20              struct {
-		Withdraw bool           `json:"withdraw"`
-		Address  common.Address `json:"address"`
-	} `json:"erc20"`
+	ExternalSystemType int32           `json:"external_system_type,string"`
+	ERC20              ContractDetails `json:"erc20"`
+	ERC721             ContractDetails `json:"erc721"`
+	ERC1155            ContractDetails `json:"erc1155"`
+}
+
+//Contract returns token standard and contract asset is withdrawn to, ok is false if withdrawals are disabled
+func (s AssetDetails) Contract() (standard string, contract ContractDetails, ok bool) {
+	switch {
+	case s.ERC20.Withdraw:
+		return token.StandardERC20, s.ERC20, true
+	case s.ERC721.Withdraw:
+		return token.StandardERC721, s.ERC721, true
+	case s.ERC1155.Withdraw:
+		return token.StandardERC1155, s.ERC1155, true
+	default:
+		return "", ContractDetails{}, false
+	}
+}
+
+//Token returns parameters of token contract asset is withdrawn to
+func (s AssetDetails) Token() token.Params {
+	standard, contract, _ := s.Contract()
+	params := token.Params{
+		Standard:   standard,
+		Address:    contract.Address,
+		Mode:       contract.Mode,
+		MintMethod: contract.MintMethod,
+	}
+	if tokenID, ok := new(big.Int).SetString(contract.TokenID, 10); ok {
+		params.TokenID = tokenID
+	}
+	return params
 }
 
 //Validate validates asset details
 func (s AssetDetails) Validate() error {
-	address := s.ERC20.Address.String()
+	standard, contract, _ := s.Contract()
+	address := contract.Address.String()
+	var tokenIDRules []validation.Rule
+	if standard == token.StandardERC1155 {
+		tokenIDRules = append(tokenIDRules, validation.Required, validation.NewStringRule(isTokenID, "must be valid token id"))
+	}
+	modeRules := []validation.Rule{validation.In(token.ModeTransfer)}
+	if standard == token.StandardERC20 {
+		modeRules = []validation.Rule{validation.In(token.ModeTransfer, token.ModeMint, token.ModeBridge)}
+	}
 	errs := validation.Errors{
 		"ExternalSystemType": validation.Validate(&s.ExternalSystemType, validation.Required, validation.Min(1)),
-		"Deposit":            validation.Validate(&s.ERC20.Withdraw, validation.Required),
+		"Deposit":            validation.Validate(&contract.Withdraw, validation.Required),
 		"Address": validation.Validate(
 			&address,
 			validation.Required,
@@ -28,11 +82,18 @@ func (s AssetDetails) Validate() error {
 				common.IsHexAddress,
 				"must be valid contract address",
 			)),
+		"TokenID": validation.Validate(&contract.TokenID, tokenIDRules...),
+		"Mode":    validation.Validate(&contract.Mode, modeRules...),
 	}
 
 	return errs.Filter()
 }
 
+func isTokenID(value string) bool {
+	tokenID, ok := new(big.Int).SetString(value, 10)
+	return ok && tokenID.Sign() >= 0
+}
+
 // Details is a composition structure which contain asset resource and it's details
 type Details struct {
 	regources.Asset
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/query"
+	"github.com/tokend/erc20-withdraw-svc/internal/token"
 	"gitlab.com/distributed_lab/logan/v3"
 	"gitlab.com/distributed_lab/logan/v3/errors"
 	"gitlab.com/distributed_lab/running"
@@ -41,7 +42,7 @@ func (s *Service) Run(ctx context.Context) {
 
 func (s *Service) processAllAssetsOnce(ctx context.Context) error {
 	active := make(map[string]bool)
-	assetsToWatch, err := s.getWatchList()
+	assetsToWatch, err := s.getWatchList(ctx)
 	if err != nil {
 		return errors.Wrap(err, "failed to get asset watch list")
 	}
@@ -60,12 +61,12 @@ func (s *Service) processAllAssetsOnce(ctx context.Context) error {
 	return nil
 }
 
-func (s *Service) getWatchList() ([]Details, error) {
+func (s *Service) getWatchList(ctx context.Context) ([]Details, error) {
 	policy := uint32(xdr.AssetPolicyWithdrawable)
 	activeAssets := uint32(0)
 	s.streamer.SetFilters(query.AssetFilters{Policy: &policy, State: &activeAssets})
 
-	assetsResponse, err := s.streamer.List()
+	assetsResponse, err := s.streamer.ListContext(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get asset list for owner", logan.F{
 			"asset_policy": policy,
@@ -79,7 +80,7 @@ func (s *Service) getWatchList() ([]Details, error) {
 
 	links := assetsResponse.Links
 	for len(assetsResponse.Data) > 0 {
-		assetsResponse, err = s.streamer.Next()
+		assetsResponse, err = s.streamer.NextContext(ctx)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to get next page of assets", logan.F{
 				"links": links,
@@ -107,7 +108,12 @@ func (s *Service) filter(assets []regources.Asset) ([]Details, error) {
 			s.log.WithError(err).Debug("bad asset details json")
 		}
 
-		if !assetDetails.ERC20.Withdraw {
+		standard, _, ok := assetDetails.Contract()
+		if !ok {
+			continue
+		}
+		if standard == token.StandardERC721 && asset.Attributes.TrailingDigits != 0 {
+			s.log.WithField("asset", asset.ID).Warn("erc721 asset must have zero trailing digits")
 			continue
 		}
 
@@ -1,18 +1,38 @@
 package withdrawer
 
 import (
+	"net/http"
 	"sync"
 
+	"github.com/tokend/erc20-withdraw-svc/internal/audit"
+	"github.com/tokend/erc20-withdraw-svc/internal/broadcast"
 	"github.com/tokend/erc20-withdraw-svc/internal/config"
+	"github.com/tokend/erc20-withdraw-svc/internal/ethpool"
+	"github.com/tokend/erc20-withdraw-svc/internal/hold"
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon"
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/getters"
+	"github.com/tokend/erc20-withdraw-svc/internal/limits"
+	"github.com/tokend/erc20-withdraw-svc/internal/safe"
+	"github.com/tokend/erc20-withdraw-svc/internal/services/admin"
+	"github.com/tokend/erc20-withdraw-svc/internal/services/screening"
 	"github.com/tokend/erc20-withdraw-svc/internal/services/watchlist"
+	"github.com/tokend/erc20-withdraw-svc/internal/storage"
+	"github.com/tokend/erc20-withdraw-svc/internal/timelock"
 	"gitlab.com/distributed_lab/logan/v3"
 	"gitlab.com/tokend/go/xdrbuild"
 )
 
 type Service struct {
 	assetWatcher   *watchlist.Service
+	admin          *admin.Service
+	screener       *screening.Service
+	holds          *hold.Store
+	auditor        audit.Recorder
+	limits         *limits.Tracker
+	timelocks      *timelock.Store
+	proposals      *safe.Store
+	private        map[string]*broadcast.PrivateSender
+	batchers       map[string]*ethpool.Batcher
 	log            *logan.Entry
 	config         config.Config
 	builder        xdrbuild.Builder
@@ -31,11 +51,79 @@ func New(cfg config.Config) *Service {
 	if err != nil {
 		cfg.Log().WithError(err).Fatal("failed to make builder")
 	}
+	screener := screening.New(screening.Opts{
+		Log:    cfg.Log(),
+		Config: cfg.ScreeningConfig(),
+	})
+	holds, err := hold.New(storage.NewFile(cfg.StorageConfig().Dir, "held_requests.json"))
+	if err != nil {
+		cfg.Log().WithError(err).Fatal("failed to load held requests")
+	}
+	auditor := audit.New(cfg.Log(), cfg.AuditConfig().Path)
+	tracker, err := limits.New(
+		storage.NewFile(cfg.StorageConfig().Dir, "withdrawal_limits.json"),
+		cfg.LimitsConfig().MaxWindow(),
+	)
+	if err != nil {
+		cfg.Log().WithError(err).Fatal("failed to load withdrawal limits")
+	}
+	timelocks, err := timelock.New(storage.NewFile(cfg.StorageConfig().Dir, "timelocks.json"))
+	if err != nil {
+		cfg.Log().WithError(err).Fatal("failed to load time locks")
+	}
+	proposals, err := safe.New(storage.NewFile(cfg.StorageConfig().Dir, "safe_transactions.json"))
+	if err != nil {
+		cfg.Log().WithError(err).Fatal("failed to load safe transactions")
+	}
+	// one private sender per chain with relay, keyed by chain name
+	private := make(map[string]*broadcast.PrivateSender)
+	for name, chain := range cfg.Chains() {
+		if chain.Relay == nil {
+			continue
+		}
+		relay := broadcast.NewRelay(http.DefaultClient, chain.Relay.Endpoint, chain.Relay.Method, chain.Relay.AuthKey())
+		sender, err := broadcast.NewPrivateSender(
+			cfg.Log().WithField("chain", name),
+			relay,
+			chain.Client,
+			chain.Relay.FallbackBlocks,
+			storage.NewFile(cfg.StorageConfig().Dir, "private_transactions_"+name+".json"),
+		)
+		if err != nil {
+			cfg.Log().WithError(err).Fatal("failed to load private transactions")
+		}
+		private[name] = sender
+	}
+	// verifiers of all assets on the chain share one batcher, keyed by chain name
+	batchers := make(map[string]*ethpool.Batcher)
+	for name, chain := range cfg.Chains() {
+		if pool, ok := chain.Client.(*ethpool.Client); ok {
+			batchers[name] = ethpool.NewBatcher(pool, ethpool.DefaultBatchPeriod, chain.Quorum)
+		}
+	}
+	adminService := admin.New(admin.Opts{
+		Log:       cfg.Log(),
+		Config:    cfg.AdminConfig(),
+		Holds:     holds,
+		Timelocks: timelocks,
+		Proposals: proposals,
+		Auditor:   auditor,
+		Limiter:   cfg.HorizonLimiter(),
+	})
 
 	return &Service{
 		log:            cfg.Log(),
 		config:         cfg,
 		assetWatcher:   assetWatcher,
+		admin:          adminService,
+		screener:       screener,
+		holds:          holds,
+		auditor:        auditor,
+		limits:         tracker,
+		timelocks:      timelocks,
+		proposals:      proposals,
+		private:        private,
+		batchers:       batchers,
 		assetsToAdd:    assetWatcher.GetToAdd(),
 		assetsToRemove: assetWatcher.GetToRemove(),
 		spawned:        sync.Map{},
@@ -0,0 +1,652 @@
+package withdrawer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/tokend/erc20-withdraw-svc/internal/broadcast"
+	"github.com/tokend/erc20-withdraw-svc/internal/config"
+	"github.com/tokend/erc20-withdraw-svc/internal/eth"
+	"github.com/tokend/erc20-withdraw-svc/internal/eth/simulated"
+	"github.com/tokend/erc20-withdraw-svc/internal/hold"
+	"github.com/tokend/erc20-withdraw-svc/internal/horizon/fake"
+	"github.com/tokend/erc20-withdraw-svc/internal/horizon/getters"
+	"github.com/tokend/erc20-withdraw-svc/internal/safe"
+	"github.com/tokend/erc20-withdraw-svc/internal/storage"
+	"github.com/tokend/erc20-withdraw-svc/internal/timelock"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	"gitlab.com/tokend/go/amount"
+	"gitlab.com/tokend/go/xdr"
+	"gitlab.com/tokend/keypair"
+	regources "gitlab.com/tokend/regources/generated"
+)
+
+const (
+	assetCode = "TKN"
+	// taskTryTransfer is a task withdraw request is created with, so oracle picks it up
+	taskTryTransfer = 2048
+	confirmations   = 3
+	timeout         = 30 * time.Second
+)
+
+// getter is a config source backed by map
+type getter map[string]map[string]interface{}
+
+func (g getter) GetStringMap(key string) (map[string]interface{}, error) {
+	return g[key], nil
+}
+
+// chainConfig replaces chains dialed from config by simulated one
+type chainConfig struct {
+	config.Config
+	chain config.Chain
+}
+
+func (c chainConfig) Chains() map[string]config.Chain {
+	return map[string]config.Chain{config.DefaultChain: c.chain}
+}
+
+// harness is a withdraw service environment: fake Horizon with single asset and simulated chain with its token
+type harness struct {
+	t       *testing.T
+	horizon *fake.Horizon
+	backend *simulated.Backend
+	token   *simulated.ERC20
+	asset   regources.Asset
+	hotKey  *ecdsa.PrivateKey
+	hot     common.Address
+	owner   keypair.Full
+	dir     string
+	// settings are config sections added to the default ones
+	settings getter
+	// relay is a private relay of the chain, if any
+	relay *config.RelayConfig
+	// client replaces simulated chain as services see it, if set
+	client eth.Client
+}
+
+// newHarness creates environment, hot wallet holds balance tokens
+func newHarness(t *testing.T, balance *big.Int) *harness {
+	hotKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hot := crypto.PubkeyToAddress(hotKey.PublicKey)
+	backend := simulated.New(core.GenesisAlloc{hot: {Balance: new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil)}})
+	opts := backend.Transactor(hotKey)
+	token, err := simulated.DeployERC20(opts, backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := token.Mint(opts, hot, balance); err != nil {
+		t.Fatal(err)
+	}
+
+	owner, err := keypair.Random()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "withdrawer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := &harness{
+		t:       t,
+		horizon: fake.New("withdrawer test network"),
+		backend: backend,
+		token:   token,
+		hotKey:  hotKey,
+		hot:     hot,
+		owner:   owner,
+		dir:     dir,
+	}
+	details, _ := json.Marshal(map[string]interface{}{
+		"external_system_type": "4",
+		"erc20": map[string]interface{}{
+			"withdraw": true,
+			"address":  token.Address,
+		},
+	})
+	h.asset = regources.Asset{
+		Key: regources.Key{ID: assetCode},
+		Attributes: regources.AssetAttributes{
+			Details:        regources.Details(details),
+			Policies:       xdr.AssetPolicyWithdrawable,
+			TrailingDigits: 6,
+		},
+		Relationships: regources.AssetRelationships{
+			Owner: &regources.Relation{Data: &regources.Key{ID: owner.Address(), Type: regources.ACCOUNTS}},
+		},
+	}
+	h.horizon.AddAsset(h.asset)
+	return h
+}
+
+func (h *harness) Close() {
+	h.horizon.Close()
+	h.backend.Close()
+	os.RemoveAll(h.dir)
+}
+
+func (h *harness) config() config.Config {
+	settings := getter{
+		"horizon": {
+			"endpoint": h.horizon.URL().String(),
+			"signer":   h.owner.Seed(),
+		},
+		"withdraw": {"signer": h.owner.Seed(), "polling_period": "1s"},
+		"storage":  {"dir": h.dir},
+		"log":      {"disable_sentry": true},
+	}
+	for key, section := range h.settings {
+		settings[key] = section
+	}
+	cfg := config.NewConfig(settings)
+	var client eth.Client = h.backend
+	if h.client != nil {
+		client = h.client
+	}
+	return chainConfig{
+		Config: cfg,
+		chain: config.Chain{
+			Name:   config.DefaultChain,
+			Client: client,
+			Transfer: config.TransferConfig{
+				Seed:          hex.EncodeToString(crypto.FromECDSA(h.hotKey)),
+				Address:       h.hot.Hex(),
+				Confirmations: confirmations,
+				// gas limit is fixed, so failing transfer is mined instead of failing estimation
+				GasLimit: 100000,
+				GasPrice: 1,
+			},
+			Relay: h.relay,
+		},
+	}
+}
+
+// start runs withdraw service, returned func stops it
+func (h *harness) start() func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	service := New(h.config())
+	done := make(chan struct{})
+	go func() {
+		service.Run(ctx)
+		close(done)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// withdraw creates withdraw request of whole tokens to the address
+func (h *harness) withdraw(address string, tokens int64) string {
+	return h.horizon.CreateWithdraw(fake.Withdraw{
+		Asset:          assetCode,
+		Amount:         regources.Amount(tokens * amount.One),
+		CreatorDetails: fmt.Sprintf(`{"address":%q}`, address),
+		Tasks:          taskTryTransfer,
+	})
+}
+
+// mineUntil mines blocks, so verifier gets new heads, until request satisfies condition
+func (h *harness) mineUntil(id string, condition func(fake.Request) bool) fake.Request {
+	deadline := time.Now().Add(timeout)
+	for {
+		request := h.horizon.Request(id)
+		if condition(request) {
+			return request
+		}
+		if time.Now().After(deadline) {
+			h.t.Fatalf("request %s has not reached expected state: %+v", id, request)
+		}
+		h.backend.Commit()
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// waitUntil waits for request to satisfy condition without mining blocks
+func (h *harness) waitUntil(id string, condition func(fake.Request) bool) fake.Request {
+	deadline := time.Now().Add(timeout)
+	for {
+		request := h.horizon.Request(id)
+		if condition(request) {
+			return request
+		}
+		if time.Now().After(deadline) {
+			h.t.Fatalf("request %s has not reached expected state: %+v", id, request)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func (h *harness) balance(address common.Address) *big.Int {
+	balance, err := h.token.BalanceOf(&bind.CallOpts{}, address)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	return balance
+}
+
+func isFinal(request fake.Request) bool {
+	return request.State != fake.StatePending
+}
+
+// isSent is true once transaction is sent and request waits for its confirmation
+func isSent(request fake.Request) bool {
+	return len(request.ExternalDetails) == 2
+}
+
+// sentDetail returns value published with request review
+func sentDetail(request fake.Request, key string) interface{} {
+	for _, raw := range request.ExternalDetails {
+		var details map[string]interface{}
+		json.Unmarshal(raw, &details)
+		if value, ok := details[key]; ok {
+			return value
+		}
+	}
+	return nil
+}
+
+func tokens(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), new(big.Int).Exp(big.NewInt(10), big.NewInt(simulated.ERC20Decimals), nil))
+}
+
+func TestHappyPath(t *testing.T) {
+	h := newHarness(t, tokens(100))
+	defer h.Close()
+	target := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	id := h.withdraw(target.Hex(), 10)
+
+	stop := h.start()
+	defer stop()
+
+	request := h.mineUntil(id, isFinal)
+	assert.Equal(t, fake.StateApproved, request.State)
+	assert.Zero(t, request.PendingTasks)
+	assert.Len(t, request.ExternalDetails, 3)
+	assert.NotNil(t, sentDetail(request, "eth_block_number"))
+	assert.Equal(t, tokens(10), h.balance(target))
+	assert.Equal(t, tokens(90), h.balance(h.hot))
+}
+
+func TestInvalidAddress(t *testing.T) {
+	h := newHarness(t, tokens(100))
+	defer h.Close()
+	missing := h.horizon.CreateWithdraw(fake.Withdraw{
+		Asset:          assetCode,
+		Amount:         regources.Amount(amount.One),
+		CreatorDetails: `{}`,
+		Tasks:          taskTryTransfer,
+	})
+	malformed := h.withdraw("0x123", 1)
+
+	stop := h.start()
+	defer stop()
+
+	for _, id := range []string{missing, malformed} {
+		request := h.waitUntil(id, isFinal)
+		assert.Equal(t, fake.StatePermanentlyRejected, request.State)
+		assert.Equal(t, "Invalid target address", request.RejectReason)
+	}
+	assert.Equal(t, tokens(100), h.balance(h.hot))
+}
+
+func TestRevert(t *testing.T) {
+	// hot wallet can't cover withdrawal, so transfer is mined, but fails
+	h := newHarness(t, tokens(1))
+	defer h.Close()
+	target := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	id := h.withdraw(target.Hex(), 10)
+
+	stop := h.start()
+	defer stop()
+
+	request := h.mineUntil(id, isFinal)
+	assert.Equal(t, fake.StatePermanentlyRejected, request.State)
+	assert.Equal(t, "Transaction failed", request.RejectReason)
+	assert.NotNil(t, sentDetail(request, "eth_tx_hash"))
+	assert.Zero(t, h.balance(target).Sign())
+}
+
+func TestReorg(t *testing.T) {
+	h := newHarness(t, tokens(100))
+	defer h.Close()
+	target := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	id := h.withdraw(target.Hex(), 10)
+
+	stop := h.start()
+	defer stop()
+
+	h.waitUntil(id, isSent)
+	dropped := h.backend.Reorg(1)
+	if !assert.Len(t, dropped, 1) {
+		return
+	}
+
+	// transaction is not on chain anymore, so request is not confirmed however many blocks are mined
+	for i := 0; i < 2*confirmations; i++ {
+		h.backend.Commit()
+		time.Sleep(100 * time.Millisecond)
+	}
+	request := h.horizon.Request(id)
+	assert.Equal(t, fake.StatePending, request.State)
+	assert.Zero(t, h.balance(target).Sign())
+
+	if !assert.NoError(t, h.backend.SendTransaction(context.Background(), dropped[0])) {
+		return
+	}
+	receipt, err := h.backend.TransactionReceipt(context.Background(), dropped[0].Hash())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	request = h.mineUntil(id, isFinal)
+	assert.Equal(t, fake.StateApproved, request.State)
+	assert.Equal(t, float64(receipt.BlockNumber.Int64()), sentDetail(request, "eth_block_number"))
+	assert.Equal(t, tokens(10), h.balance(target))
+}
+
+func TestRestart(t *testing.T) {
+	h := newHarness(t, tokens(100))
+	defer h.Close()
+	target := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	id := h.withdraw(target.Hex(), 10)
+
+	stop := h.start()
+	h.waitUntil(id, isSent)
+	stop()
+
+	for i := 0; i < confirmations; i++ {
+		h.backend.Commit()
+	}
+	stop = h.start()
+	defer stop()
+
+	request := h.mineUntil(id, isFinal)
+	assert.Equal(t, fake.StateApproved, request.State)
+	// withdrawal is sent exactly once: token deployment, mint and transfer
+	nonce, err := h.backend.PendingNonceAt(context.Background(), h.hot)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), nonce)
+	assert.Equal(t, tokens(10), h.balance(target))
+}
+
+func TestAssetRemoval(t *testing.T) {
+	if testing.Short() {
+		t.Skip("asset list is polled every 20 seconds")
+	}
+	h := newHarness(t, tokens(100))
+	defer h.Close()
+	target := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	first := h.withdraw(target.Hex(), 10)
+
+	stop := h.start()
+	defer stop()
+	h.mineUntil(first, isFinal)
+
+	// asset which is not withdrawable anymore is removed from watch list
+	asset := h.asset
+	asset.Attributes.Policies = 0
+	h.horizon.AddAsset(asset)
+	listed := len(h.horizon.Calls())
+	deadline := time.Now().Add(timeout)
+	for !hasCall(h.horizon.Calls()[listed:], "/v3/assets", "") {
+		if time.Now().After(deadline) {
+			t.Fatal("asset list was not polled again")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	// services of asset are stopped right after asset list is processed
+	time.Sleep(time.Second)
+
+	removed := len(h.horizon.Calls())
+	second := h.withdraw(target.Hex(), 10)
+	// oracle would poll requests in 15 seconds, while verifier every second
+	for i := 0; i < 80; i++ {
+		h.backend.Commit()
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	assert.False(t, hasCall(h.horizon.Calls()[removed:], "/v3/create_withdraw_requests", assetCode))
+	request := h.horizon.Request(second)
+	assert.Equal(t, fake.StatePending, request.State)
+	assert.Equal(t, uint32(taskTryTransfer), request.PendingTasks)
+	assert.Equal(t, tokens(10), h.balance(target))
+}
+
+func TestVerifierListing(t *testing.T) {
+	h := newHarness(t, tokens(100))
+	defer h.Close()
+	target := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	id := h.withdraw(target.Hex(), 10)
+
+	stop := h.start()
+	defer stop()
+	h.mineUntil(id, isSent)
+
+	// blocks are mined much faster than polling period, but requests are listed on polling cadence
+	listed := len(h.horizon.Calls())
+	start := time.Now()
+	for i := 0; i < 40; i++ {
+		h.backend.Commit()
+		time.Sleep(50 * time.Millisecond)
+	}
+	elapsed := time.Since(start)
+
+	verifierLists := 0
+	for _, call := range h.horizon.Calls()[listed:] {
+		u, err := url.Parse(call)
+		if err != nil || u.Path != "/v3/create_withdraw_requests" {
+			continue
+		}
+		if u.Query().Get("filter[pending_tasks]") != fmt.Sprint(taskTryTransfer) {
+			verifierLists++
+		}
+	}
+	assert.True(t, verifierLists <= int(elapsed/time.Second)+1, "verifier listed requests %d times", verifierLists)
+
+	// confirmation is still noticed on head
+	request := h.mineUntil(id, isFinal)
+	assert.Equal(t, fake.StateApproved, request.State)
+}
+
+func TestTimelockPublishesDetails(t *testing.T) {
+	h := newHarness(t, tokens(100))
+	defer h.Close()
+	h.settings = getter{
+		"timelock": {"assets": map[string]interface{}{
+			assetCode: map[string]interface{}{"threshold": "1", "delay": "1h"},
+		}},
+	}
+	target := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	id := h.withdraw(target.Hex(), 10)
+	before := h.horizon.Request(id)
+
+	stop := h.start()
+	defer stop()
+
+	// send time is published by review which must leave request on its tasks
+	request := h.waitUntil(id, func(request fake.Request) bool {
+		return sentDetail(request, "scheduled_send_at") != nil
+	})
+	assert.Equal(t, fake.StatePending, request.State)
+	assert.Equal(t, before.PendingTasks, request.PendingTasks)
+	assert.Equal(t, before.AllTasks, request.AllTasks)
+	assert.Len(t, request.ExternalDetails, 1)
+
+	// request is not sent and not reviewed again while locked
+	for i := 0; i < 10; i++ {
+		h.backend.Commit()
+		time.Sleep(200 * time.Millisecond)
+	}
+	request = h.horizon.Request(id)
+	assert.Equal(t, fake.StatePending, request.State)
+	assert.Equal(t, before.PendingTasks, request.PendingTasks)
+	assert.Len(t, request.ExternalDetails, 1)
+	assert.Zero(t, h.balance(target).Sign())
+}
+
+// hasCall returns true if any of calls is to path, filtered by asset if it is not empty
+func hasCall(calls []string, path, asset string) bool {
+	for _, call := range calls {
+		u, err := url.Parse(call)
+		if err != nil || u.Path != path {
+			continue
+		}
+		if asset == "" || u.Query().Get("filter[request_details.asset]") == asset {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPrune(t *testing.T) {
+	h := newHarness(t, tokens(100))
+	defer h.Close()
+	// pending request no service acts on, so it stays pending
+	pending := h.horizon.CreateWithdraw(fake.Withdraw{Asset: assetCode, Amount: amount.One, Tasks: 1})
+	// request unknown to Horizon is not pending anymore
+	gone := "999"
+
+	holds, err := hold.New(storage.NewFile(h.dir, "held_requests.json"))
+	assert.NoError(t, err)
+	timelocks, err := timelock.New(storage.NewFile(h.dir, "timelocks.json"))
+	assert.NoError(t, err)
+	proposals, err := safe.New(storage.NewFile(h.dir, "safe_transactions.json"))
+	assert.NoError(t, err)
+	for i, id := range []string{pending, gone} {
+		assert.NoError(t, holds.Hold(hold.Request{ID: id, Asset: assetCode}))
+		_, err = timelocks.Lock(timelock.Lock{ID: id, Asset: assetCode})
+		assert.NoError(t, err)
+		_, err = proposals.Propose(safe.Proposal{ID: id, Asset: assetCode, Safe: "0xsafe", Nonce: uint64(i)})
+		assert.NoError(t, err)
+	}
+
+	service := New(h.config())
+	assert.NoError(t, service.prune(context.Background(), getters.NewDefaultCreateWithdrawRequestHandler(service.config.Horizon())))
+
+	// pruning is persisted, so it is seen after restart
+	holds, err = hold.New(storage.NewFile(h.dir, "held_requests.json"))
+	assert.NoError(t, err)
+	timelocks, err = timelock.New(storage.NewFile(h.dir, "timelocks.json"))
+	assert.NoError(t, err)
+	proposals, err = safe.New(storage.NewFile(h.dir, "safe_transactions.json"))
+	assert.NoError(t, err)
+	for _, store := range []func(id string) bool{
+		func(id string) bool { _, ok := holds.Get(id); return ok },
+		func(id string) bool { _, ok := timelocks.Get(id); return ok },
+		func(id string) bool { _, ok := proposals.Get(id); return ok },
+	} {
+		assert.True(t, store(pending))
+		assert.False(t, store(gone))
+	}
+}
+
+func TestPrivateThenPublic(t *testing.T) {
+	h := newHarness(t, tokens(100))
+	defer h.Close()
+	// relay accepts transactions, but they are only mined once test includes them
+	var mu sync.Mutex
+	var held []string
+	relay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Params []map[string]interface{} `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		mu.Lock()
+		held = append(held, request.Params[0]["tx"].(string))
+		mu.Unlock()
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x"}`))
+	}))
+	defer relay.Close()
+	h.relay = &config.RelayConfig{Endpoint: relay.URL, Method: broadcast.MethodPrivate, FallbackBlocks: 100}
+	h.settings = getter{
+		"private": {"assets": map[string]interface{}{
+			assetCode: map[string]interface{}{"threshold": "5"},
+		}},
+	}
+	target := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	large := h.withdraw(target.Hex(), 10)
+	small := h.withdraw(target.Hex(), 1)
+
+	stop := h.start()
+	defer stop()
+
+	h.waitUntil(large, isSent)
+	h.waitUntil(small, isSent)
+	mu.Lock()
+	submitted := append([]string{}, held...)
+	mu.Unlock()
+	if !assert.Len(t, submitted, 1) {
+		return
+	}
+
+	// public withdrawal follows private one, so the latter is still valid once relay includes it
+	tx := new(types.Transaction)
+	assert.NoError(t, rlp.DecodeBytes(hexutil.MustDecode(submitted[0]), tx))
+	assert.NoError(t, h.backend.SendTransaction(context.Background(), tx))
+
+	for _, id := range []string{large, small} {
+		request := h.mineUntil(id, isFinal)
+		assert.Equal(t, fake.StateApproved, request.State)
+	}
+	assert.Equal(t, tokens(11), h.balance(target))
+}
+
+// unavailable is a chain which nodes can't be reached while down is set
+type unavailable struct {
+	*simulated.Backend
+	down int32
+}
+
+func (u *unavailable) ChainID(ctx context.Context) (*big.Int, error) {
+	if atomic.LoadInt32(&u.down) == 1 {
+		return nil, errors.New("no nodes are available")
+	}
+	return u.Backend.ChainID(ctx)
+}
+
+func TestChainUnavailableOnSpawn(t *testing.T) {
+	h := newHarness(t, tokens(100))
+	defer h.Close()
+	client := &unavailable{Backend: h.backend, down: 1}
+	h.client = client
+	target := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	id := h.withdraw(target.Hex(), 10)
+
+	stop := h.start()
+	defer stop()
+
+	time.Sleep(2 * time.Second)
+	assert.Equal(t, fake.StatePending, h.horizon.Request(id).State)
+
+	// asset services are started once chain is back, without restart
+	atomic.StoreInt32(&client.down, 0)
+	request := h.mineUntil(id, isFinal)
+	assert.Equal(t, fake.StateApproved, request.State)
+	assert.Equal(t, tokens(10), h.balance(target))
+}
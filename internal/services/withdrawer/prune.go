@@ -0,0 +1,70 @@
+package withdrawer
+
+import (
+	"context"
+	"time"
+
+	"github.com/tokend/erc20-withdraw-svc/internal/horizon/client"
+	"github.com/tokend/erc20-withdraw-svc/internal/horizon/getters"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	"gitlab.com/distributed_lab/running"
+)
+
+const (
+	pruneInterval = 10 * time.Minute
+
+	reviewableRequestStatePending = 1
+)
+
+// pruner removes held requests, time locks and Safe proposals of requests which are not pending anymore,
+// right after start and periodically, so stores hold only requests service may still act on
+func (s *Service) pruner(ctx context.Context) {
+	defer s.Done()
+	requests := getters.NewDefaultCreateWithdrawRequestHandler(s.config.Horizon())
+	running.WithBackOff(ctx, s.log, "pruner", func(ctx context.Context) error {
+		return s.prune(ctx, requests)
+	}, pruneInterval, pruneInterval, time.Hour)
+}
+
+func (s *Service) prune(ctx context.Context, requests getters.CreateWithdrawRequestGetter) error {
+	ids := make(map[string]struct{})
+	for _, request := range s.holds.List() {
+		ids[request.ID] = struct{}{}
+	}
+	for _, lock := range s.timelocks.List() {
+		ids[lock.ID] = struct{}{}
+	}
+	for _, proposal := range s.proposals.List() {
+		ids[proposal.ID] = struct{}{}
+	}
+
+	var done []string
+	for id := range ids {
+		request, err := requests.ByIDContext(ctx, id)
+		switch {
+		case client.IsNotFound(err):
+		case err != nil:
+			return errors.Wrap(err, "failed to get request", logan.F{"request_id": id})
+		case request.Data.Attributes.StateI == reviewableRequestStatePending:
+			continue
+		}
+		done = append(done, id)
+	}
+	if len(done) == 0 {
+		return nil
+	}
+
+	if err := s.holds.Prune(done...); err != nil {
+		return errors.Wrap(err, "failed to prune held requests")
+	}
+	if err := s.timelocks.Prune(done...); err != nil {
+		return errors.Wrap(err, "failed to prune time locks")
+	}
+	if err := s.proposals.Prune(done...); err != nil {
+		return errors.Wrap(err, "failed to prune safe transactions")
+	}
+	s.log.WithField("requests", len(done)).Info("pruned records of requests which are not pending anymore")
+
+	return nil
+}
@@ -2,22 +2,35 @@ package withdrawer
 
 import (
 	"context"
+	"time"
 
+	"github.com/tokend/erc20-withdraw-svc/internal/broadcast"
+	"github.com/tokend/erc20-withdraw-svc/internal/config"
 	"github.com/tokend/erc20-withdraw-svc/internal/horizon/getters"
-	"github.com/tokend/erc20-withdraw-svc/internal/horizon/submit"
 	"github.com/tokend/erc20-withdraw-svc/internal/services/oracle"
 	"github.com/tokend/erc20-withdraw-svc/internal/services/verifier"
 	"github.com/tokend/erc20-withdraw-svc/internal/services/watchlist"
 	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	"gitlab.com/distributed_lab/running"
 )
 
 func (s *Service) Run(ctx context.Context) {
 	s.log.Info("service is started")
 	go s.assetWatcher.Run(ctx)
+	go s.screener.Run(ctx)
+	go s.admin.Run(ctx)
+	for _, sender := range s.private {
+		go sender.Run(ctx)
+	}
+	for _, batcher := range s.batchers {
+		go batcher.Run(ctx)
+	}
 
-	s.Add(2)
+	s.Add(3)
 	go s.spawner(ctx)
 	go s.cancellor(ctx)
+	go s.pruner(ctx)
 	s.Wait()
 }
 
@@ -41,40 +54,93 @@ func (s *Service) cancellor(ctx context.Context) {
 	}
 }
 
+// spawn starts services of the asset, creation is retried in background while asset chain is unavailable
 func (s *Service) spawn(ctx context.Context, details watchlist.Details) {
 	fields := logan.F{"asset_code": details.ID}
 
-	oracleService := oracle.New(oracle.Opts{
+	_, contract, _ := details.Contract()
+	chainName := contract.Chain
+	if chainName == "" {
+		chainName = config.DefaultChain
+	}
+	chain, ok := s.config.Chains()[chainName]
+	if !ok {
+		s.log.WithFields(fields).WithField("chain", chainName).Error("asset chain is not configured, skipping this asset")
+		return
+	}
+
+	// asset is stored before services are created, so its removal stops retries as well
+	innerCtx, cancelFunc := context.WithCancel(ctx)
+	s.spawned.Store(details.Asset.ID, cancelFunc)
+
+	go running.UntilSuccess(innerCtx, s.log.WithFields(fields), "spawner", func(ctx context.Context) (bool, error) {
+		started, err := s.start(ctx, details, chain)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to create services of asset", fields)
+		}
+		if !started {
+			s.log.WithFields(fields).Warn("oracle service is nil, skipping this asset")
+			cancelFunc()
+			s.spawned.Delete(details.Asset.ID)
+		}
+		return true, nil
+	}, 5*time.Second, 5*time.Minute)
+}
+
+// start runs oracle and verifier of the asset until ctx is cancelled, returns false if oracle refuses the asset
+func (s *Service) start(ctx context.Context, details watchlist.Details, chain config.Chain) (bool, error) {
+	// typed nil must not get into interface, so sender is only set for chains with relay
+	var private broadcast.Sender
+	if sender, ok := s.private[chain.Name]; ok {
+		private = sender
+	}
+
+	oracleService, err := oracle.New(oracle.Opts{
 		Builder:   s.builder,
 		Log:       s.log,
 		Config:    s.config,
-		Submitter: submit.New(s.config.Horizon()),
-		Client:    *s.config.EthClient(),
+		Submitter: s.config.Submitter(),
+		Chain:     chain,
 		Asset:     details,
+		Screener:  s.screener,
+		Holds:     s.holds,
+		Auditor:   s.auditor,
+		Limits:    s.limits,
+		Timelocks: s.timelocks,
+		Assets:    getters.NewDefaultAssetHandler(s.config.Horizon()),
+		Proposals: s.proposals,
+		Private:   private,
 
 		Streamer: getters.NewDefaultCreateWithdrawRequestHandler(s.config.Horizon()),
 	})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create oracle")
+	}
 	if oracleService == nil {
-		s.log.WithFields(fields).Warn("oracle service is nil, skipping this asset")
-		return
+		return false, nil
+	}
+
+	// typed nil must not get into interface as well
+	var lookups verifier.Lookups
+	if batcher, ok := s.batchers[chain.Name]; ok {
+		lookups = batcher
 	}
 
 	verifierService := verifier.New(verifier.Opts{
 		Builder:   s.builder,
 		Log:       s.log,
 		Config:    s.config,
-		Submitter: submit.New(s.config.Horizon()),
-		Client:    *s.config.EthClient(),
+		Submitter: s.config.Submitter(),
+		Chain:     chain,
+		Lookups:   lookups,
 		Asset:     details,
 
 		Streamer: getters.NewDefaultCreateWithdrawRequestHandler(s.config.Horizon()),
 	})
 
-	innerCtx, cancelFunc := context.WithCancel(ctx)
-	s.spawned.Store(details.Asset.ID, cancelFunc)
-
-	go oracleService.Run(innerCtx)
-	go verifierService.Run(innerCtx)
+	go oracleService.Run(ctx)
+	go verifierService.Run(ctx)
 
-	s.log.WithFields(fields).Info("Started listening for withdrawals")
+	s.log.WithField("asset_code", details.ID).Info("Started listening for withdrawals")
+	return true, nil
 }
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// File persists JSON encoded state in a single file on local disk
+type File struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFile creates file storage named name inside of dir
+func NewFile(dir, name string) *File {
+	return &File{
+		path: filepath.Join(dir, name),
+	}
+}
+
+// Path returns path to the underlying file
+func (f *File) Path() string {
+	return f.path
+}
+
+// Load decodes stored state into v, v is left untouched if nothing was stored yet
+func (f *File) Load(v interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	raw, err := ioutil.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to read file", logan.F{"path": f.path})
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		return errors.Wrap(err, "failed to unmarshal file", logan.F{"path": f.path})
+	}
+
+	return nil
+}
+
+// Save atomically replaces stored state with v
+func (f *File) Save(v interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal state")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return errors.Wrap(err, "failed to create storage dir", logan.F{"path": f.path})
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary file", logan.F{"path": f.path})
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "failed to write temporary file", logan.F{"path": tmp.Name()})
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "failed to sync temporary file", logan.F{"path": tmp.Name()})
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to close temporary file", logan.F{"path": tmp.Name()})
+	}
+
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return errors.Wrap(err, "failed to replace file", logan.F{"path": f.path})
+	}
+
+	return nil
+}
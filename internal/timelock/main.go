@@ -0,0 +1,205 @@
+package timelock
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tokend/erc20-withdraw-svc/internal/storage"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// State is a state of time locked withdrawal request
+type State string
+
+const (
+	// StateLocked means request waits for its send time and still can be cancelled
+	StateLocked State = "locked"
+	// StateReleased means lock expired and request is being sent
+	StateReleased State = "released"
+	// StateCancelled means request was cancelled during the lock and should be rejected in TokenD
+	StateCancelled State = "cancelled"
+)
+
+var (
+	ErrNotFound     = errors.New("time lock not found")
+	ErrNotCancelled = errors.New("time lock is not active anymore")
+	ErrCancelled    = errors.New("time lock was cancelled")
+)
+
+// Lock is delay withdrawal request has to wait before it is sent
+type Lock struct {
+	ID       string    `json:"id"`
+	Asset    string    `json:"asset"`
+	Address  string    `json:"address"`
+	Amount   string    `json:"amount"`
+	LockedAt time.Time `json:"locked_at"`
+	SendAt   time.Time `json:"send_at"`
+	State    State     `json:"state"`
+	// Published is set once send time is written to request external details
+	Published bool `json:"published"`
+
+	CancelledBy string     `json:"cancelled_by,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	Comment     string     `json:"comment,omitempty"`
+}
+
+// Store keeps time locks persisted across restarts
+type Store struct {
+	file  *storage.File
+	mu    sync.RWMutex
+	locks map[string]Lock
+}
+
+// New creates store persisted in file, loading previously created locks
+func New(file *storage.File) (*Store, error) {
+	locks := make(map[string]Lock)
+	if err := file.Load(&locks); err != nil {
+		return nil, errors.Wrap(err, "failed to load time locks")
+	}
+
+	return &Store{
+		file:  file,
+		locks: locks,
+	}, nil
+}
+
+// Lock creates lock for the request, locking the same request twice returns the first lock
+func (s *Store) Lock(lock Lock) (*Lock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.locks[lock.ID]; ok {
+		return &existing, nil
+	}
+
+	lock.State = StateLocked
+	s.locks[lock.ID] = lock
+	if err := s.file.Save(s.locks); err != nil {
+		delete(s.locks, lock.ID)
+		return nil, errors.Wrap(err, "failed to persist time lock")
+	}
+
+	return &lock, nil
+}
+
+// Published marks send time of the lock as written to request external details
+func (s *Store) Published(id string) error {
+	return s.update(id, func(lock *Lock) error {
+		lock.Published = true
+		return nil
+	})
+}
+
+// Release moves expired lock into released state, so it can't be cancelled anymore
+func (s *Store) Release(id string) error {
+	return s.update(id, func(lock *Lock) error {
+		switch lock.State {
+		case StateCancelled:
+			return ErrCancelled
+		case StateLocked:
+			lock.State = StateReleased
+		}
+		return nil
+	})
+}
+
+// Cancel cancels active lock on behalf of canceller
+func (s *Store) Cancel(id, canceller, comment string) (*Lock, error) {
+	var result Lock
+	err := s.update(id, func(lock *Lock) error {
+		if lock.State != StateLocked {
+			return ErrNotCancelled
+		}
+		now := time.Now().UTC()
+		lock.State = StateCancelled
+		lock.CancelledBy = canceller
+		lock.CancelledAt = &now
+		lock.Comment = comment
+		result = *lock
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Get returns lock by request id
+func (s *Store) Get(id string) (*Lock, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lock, ok := s.locks[id]
+	if !ok {
+		return nil, false
+	}
+
+	return &lock, true
+}
+
+// Prune removes locks of requests which left pending state, store is persisted once for all of them
+func (s *Store) Prune(ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := make(map[string]Lock, len(ids))
+	for _, id := range ids {
+		if value, ok := s.locks[id]; ok {
+			removed[id] = value
+			delete(s.locks, id)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	if err := s.file.Save(s.locks); err != nil {
+		for id, value := range removed {
+			s.locks[id] = value
+		}
+		return errors.Wrap(err, "failed to persist time locks")
+	}
+
+	return nil
+}
+
+// List returns all known locks ordered by send time
+func (s *Store) List() []Lock {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Lock, 0, len(s.locks))
+	for _, lock := range s.locks {
+		result = append(result, lock)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].SendAt.Before(result[j].SendAt)
+	})
+
+	return result
+}
+
+func (s *Store) update(id string, fn func(lock *Lock) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, ok := s.locks[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	previous := lock
+	if err := fn(&lock); err != nil {
+		return err
+	}
+	s.locks[id] = lock
+
+	if err := s.file.Save(s.locks); err != nil {
+		s.locks[id] = previous
+		return errors.Wrap(err, "failed to persist time lock")
+	}
+
+	return nil
+}
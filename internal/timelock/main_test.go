@@ -0,0 +1,83 @@
+package timelock
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tokend/erc20-withdraw-svc/internal/storage"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "timelock")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := New(storage.NewFile(dir, "timelocks.json"))
+	assert.NoError(t, err)
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, lock := range []Lock{
+		{ID: "1", Asset: "TKN", Amount: "10", LockedAt: now, SendAt: now.Add(2 * time.Hour)},
+		{ID: "2", Asset: "TKN", Amount: "20", LockedAt: now, SendAt: now.Add(time.Hour)},
+		{ID: "3", Asset: "TKN", Amount: "30", LockedAt: now, SendAt: now.Add(3 * time.Hour)},
+	} {
+		created, err := store.Lock(lock)
+		assert.NoError(t, err)
+		assert.Equal(t, StateLocked, created.State)
+	}
+	// locking the same request again returns the first lock
+	existing, err := store.Lock(Lock{ID: "1", Asset: "TKN", SendAt: now})
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(2*time.Hour), existing.SendAt)
+
+	locks := store.List()
+	if assert.Len(t, locks, 3) {
+		assert.Equal(t, "2", locks[0].ID)
+		assert.Equal(t, "1", locks[1].ID)
+		assert.Equal(t, "3", locks[2].ID)
+	}
+
+	assert.NoError(t, store.Published("1"))
+	assert.Equal(t, ErrNotFound, store.Published("4"))
+
+	// released lock can't be cancelled anymore
+	assert.NoError(t, store.Release("2"))
+	assert.NoError(t, store.Release("2"))
+	_, err = store.Cancel("2", "alice", "")
+	assert.Equal(t, ErrNotCancelled, err)
+
+	// cancelled lock can't be released
+	cancelled, err := store.Cancel("3", "alice", "suspicious")
+	assert.NoError(t, err)
+	assert.Equal(t, StateCancelled, cancelled.State)
+	assert.Equal(t, "alice", cancelled.CancelledBy)
+	assert.NotNil(t, cancelled.CancelledAt)
+	assert.Equal(t, ErrCancelled, store.Release("3"))
+	_, err = store.Cancel("3", "bob", "")
+	assert.Equal(t, ErrNotCancelled, err)
+	_, err = store.Cancel("4", "alice", "")
+	assert.Equal(t, ErrNotFound, err)
+
+	// locks are persisted, so they are seen after restart
+	store, err = New(storage.NewFile(dir, "timelocks.json"))
+	assert.NoError(t, err)
+	lock, ok := store.Get("1")
+	if assert.True(t, ok) {
+		assert.Equal(t, StateLocked, lock.State)
+		assert.True(t, lock.Published)
+		assert.True(t, lock.SendAt.Equal(now.Add(2*time.Hour)))
+	}
+	lock, ok = store.Get("2")
+	if assert.True(t, ok) {
+		assert.Equal(t, StateReleased, lock.State)
+	}
+	lock, ok = store.Get("3")
+	if assert.True(t, ok) {
+		assert.Equal(t, StateCancelled, lock.State)
+		assert.Equal(t, "suspicious", lock.Comment)
+	}
+	_, ok = store.Get("4")
+	assert.False(t, ok)
+}
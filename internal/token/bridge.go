@@ -0,0 +1,186 @@
+package token
+
+import (
+	"context"
+	"encoding/binary"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	// DefaultBridgeMethod is used by bridge mode if method is not configured
+	DefaultBridgeMethod = "withdraw"
+	// DefaultBridgeEvent is used by bridge mode if event is not configured
+	DefaultBridgeEvent = "Withdrawn"
+)
+
+// Bridge describes contract ERC20 withdrawals are sent through in bridge mode
+type Bridge struct {
+	Address common.Address
+	// ABI is a JSON ABI of the bridge contract
+	ABI string
+	// Method is a name of `method(address token, address to, uint256 amount, bytes32 requestRef)`
+	Method string
+	// Event is a name of event emitted on withdrawal, it must have `address token`, `address to`, `uint256 amount`
+	// and `bytes32 requestRef` arguments, addresses in this order, while the rest are told by type
+	Event string
+}
+
+// RequestRef returns reference of TokenD withdraw request put on-chain by bridge contract,
+// which is keccak256 of big endian request id followed by request hash
+func RequestRef(requestID, requestHash string) (common.Hash, error) {
+	id, err := strconv.ParseUint(requestID, 10, 64)
+	if err != nil {
+		return common.Hash{}, errors.Wrap(err, "failed to parse request id")
+	}
+	hash := common.FromHex(requestHash)
+	if len(hash) != common.HashLength {
+		return common.Hash{}, errors.From(errors.New("invalid request hash"), logan.F{"hash": requestHash})
+	}
+
+	var idBytes [8]byte
+	binary.BigEndian.PutUint64(idBytes[:], id)
+	return crypto.Keccak256Hash(idBytes[:], hash), nil
+}
+
+type bridge struct {
+	*bound
+	token  *erc20
+	method string
+	event  string
+	args   eventArgs
+}
+
+// eventArgs are names of bridge event arguments
+type eventArgs struct {
+	token  string
+	to     string
+	amount string
+	ref    string
+}
+
+func newBridge(tokenAddress common.Address, params Bridge, backend bind.ContractBackend) (*bridge, error) {
+	b, err := newBound(params.ABI, params.Address, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to bind bridge contract")
+	}
+	if params.Method == "" {
+		params.Method = DefaultBridgeMethod
+	}
+	if params.Event == "" {
+		params.Event = DefaultBridgeEvent
+	}
+
+	method, ok := b.abi.Methods[params.Method]
+	if !ok || !argumentTypes(method.Inputs, "address", "address", "uint256", "bytes32") {
+		return nil, errors.From(errors.New("bridge method must be method(address,address,uint256,bytes32)"), logan.F{
+			"method": params.Method,
+		})
+	}
+	event, ok := b.abi.Events[params.Event]
+	if !ok {
+		return nil, errors.From(errors.New("bridge event not found in ABI"), logan.F{"event": params.Event})
+	}
+	args, ok := bridgeEventArgs(event.Inputs)
+	if !ok {
+		return nil, errors.From(errors.New("bridge event must have address token, address to, uint256 amount and bytes32 ref arguments"), logan.F{
+			"event": params.Event,
+		})
+	}
+
+	token, err := newERC20(tokenAddress, "", backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to bind token contract")
+	}
+
+	return &bridge{
+		bound:  b,
+		token:  token,
+		method: params.Method,
+		event:  params.Event,
+		args:   args,
+	}, nil
+}
+
+// bridgeEventArgs finds names of bridge event arguments by their types: the first of two addresses is token
+// and the second one is recipient, the same way they go in bridge method, amount and ref are the only uint256 and bytes32
+func bridgeEventArgs(inputs abi.Arguments) (eventArgs, bool) {
+	var args eventArgs
+	var addresses, amounts, refs []string
+	for _, input := range inputs {
+		switch input.Type.String() {
+		case "address":
+			addresses = append(addresses, input.Name)
+		case "uint256":
+			amounts = append(amounts, input.Name)
+		case "bytes32":
+			refs = append(refs, input.Name)
+		}
+	}
+	if len(addresses) != 2 || len(amounts) != 1 || len(refs) != 1 || len(inputs) != 4 {
+		return args, false
+	}
+	args.token, args.to, args.amount, args.ref = addresses[0], addresses[1], amounts[0], refs[0]
+	return args, true
+}
+
+func argumentTypes(args abi.Arguments, types ...string) bool {
+	if len(args) != len(types) {
+		return false
+	}
+	for i, arg := range args {
+		if arg.Type.String() != types[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *bridge) Decimals(ctx context.Context) (uint32, error) {
+	return t.token.Decimals(ctx)
+}
+
+func (t *bridge) Send(opts *bind.TransactOpts, transfer Transfer) (*types.Transaction, error) {
+	return t.contract.Transact(opts, t.method, t.token.address, transfer.To, transfer.Amount, [32]byte(transfer.Ref))
+}
+
+// Delivered matches bridge event by request ref, which is unique per request, and checks token, recipient and amount
+func (t *bridge) Delivered(log types.Log, transfer Transfer) bool {
+	if transfer.Ref == (common.Hash{}) {
+		return false
+	}
+
+	fields, ok := t.eventFields(t.event, log)
+	if !ok {
+		return false
+	}
+	token, ok := fields[t.args.token].(common.Address)
+	if !ok || token != t.token.address {
+		return false
+	}
+	to, ok := fields[t.args.to].(common.Address)
+	if !ok || to != transfer.To {
+		return false
+	}
+	amount, ok := fields[t.args.amount].(*big.Int)
+	if !ok || transfer.Amount == nil || amount.Cmp(transfer.Amount) != 0 {
+		return false
+	}
+	// indexed bytes32 is unpacked as a slice, non indexed one as an array
+	switch ref := fields[t.args.ref].(type) {
+	case [32]byte:
+		return common.Hash(ref) == transfer.Ref
+	case []byte:
+		return common.BytesToHash(ref) == transfer.Ref && len(ref) == common.HashLength
+	default:
+		return false
+	}
+}
@@ -0,0 +1,54 @@
+package token
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const erc1155ABI = `[
+	{"constant":false,"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"id","type":"uint256"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"}],"name":"safeTransferFrom","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"operator","type":"address"},{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"id","type":"uint256"},{"indexed":false,"name":"value","type":"uint256"}],"name":"TransferSingle","type":"event"}
+]`
+
+// erc1155 is a single token id within ERC-1155 contract, amounts of which are whole units
+type erc1155 struct {
+	*bound
+	tokenID *big.Int
+}
+
+func newERC1155(address common.Address, tokenID *big.Int, backend bind.ContractBackend) (*erc1155, error) {
+	b, err := newBound(erc1155ABI, address, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &erc1155{bound: b, tokenID: tokenID}, nil
+}
+
+// Decimals of ERC-1155 tokens are zero, as standard does not define fractional amounts
+func (t *erc1155) Decimals(ctx context.Context) (uint32, error) {
+	return 0, nil
+}
+
+func (t *erc1155) Send(opts *bind.TransactOpts, transfer Transfer) (*types.Transaction, error) {
+	return t.contract.Transact(opts, "safeTransferFrom", opts.From, transfer.To, t.tokenID, transfer.Amount, []byte{})
+}
+
+func (t *erc1155) Delivered(log types.Log, transfer Transfer) bool {
+	var event struct {
+		Operator common.Address
+		From     common.Address
+		To       common.Address
+		// named after ABI argument, so log can be unpacked into it
+		Id    *big.Int
+		Value *big.Int
+	}
+	if !t.event(&event, "TransferSingle", log) {
+		return false
+	}
+
+	return event.To == transfer.To && event.Id.Cmp(t.tokenID) == 0 && event.Value.Cmp(transfer.Amount) == 0
+}
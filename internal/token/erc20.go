@@ -0,0 +1,98 @@
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const erc20ABI = "[{\"constant\":true,\"inputs\":[],\"name\":\"name\",\"outputs\":[{\"name\":\"\",\"type\":\"string\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"_spender\",\"type\":\"address\"},{\"name\":\"_value\",\"type\":\"uint256\"}],\"name\":\"approve\",\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"totalSupply\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"_from\",\"type\":\"address\"},{\"name\":\"_to\",\"type\":\"address\"},{\"name\":\"_value\",\"type\":\"uint256\"}],\"name\":\"transferFrom\",\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"decimals\",\"outputs\":[{\"name\":\"\",\"type\":\"uint8\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"_owner\",\"type\":\"address\"}],\"name\":\"balanceOf\",\"outputs\":[{\"name\":\"balance\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"symbol\",\"outputs\":[{\"name\":\"\",\"type\":\"string\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"_to\",\"type\":\"address\"},{\"name\":\"_value\",\"type\":\"uint256\"}],\"name\":\"transfer\",\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"_owner\",\"type\":\"address\"},{\"name\":\"_spender\",\"type\":\"address\"}],\"name\":\"allowance\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"payable\":true,\"stateMutability\":\"payable\",\"type\":\"fallback\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"name\":\"owner\",\"type\":\"address\"},{\"indexed\":true,\"name\":\"spender\",\"type\":\"address\"},{\"indexed\":false,\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Approval\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"name\":\"from\",\"type\":\"address\"},{\"indexed\":true,\"name\":\"to\",\"type\":\"address\"},{\"indexed\":false,\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Transfer\",\"type\":\"event\"}]"
+
+// mintABI is an ABI entry of `method(address to, uint256 amount)` minting tokens
+const mintABI = `{"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"name":%s,"outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"}`
+
+type erc20 struct {
+	*bound
+	// mintMethod is set if tokens are minted instead of being transferred from hot wallet
+	mintMethod string
+}
+
+func newERC20(address common.Address, mintMethod string, backend bind.ContractBackend) (*erc20, error) {
+	rawABI := erc20ABI
+	if mintMethod != "" {
+		name, err := json.Marshal(mintMethod)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal mint method name")
+		}
+		rawABI = strings.TrimSuffix(erc20ABI, "]") + "," + fmt.Sprintf(mintABI, name) + "]"
+	}
+
+	b, err := newBound(rawABI, address, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &erc20{bound: b, mintMethod: mintMethod}, nil
+}
+
+func (t *erc20) Decimals(ctx context.Context) (uint32, error) {
+	decimals := new(uint8)
+	if err := t.contract.Call(&bind.CallOpts{Context: ctx}, decimals, "decimals"); err != nil {
+		return 0, errors.Wrap(err, "failed to get decimals of erc20 contract")
+	}
+	return uint32(*decimals), nil
+}
+
+func (t *erc20) Send(opts *bind.TransactOpts, transfer Transfer) (*types.Transaction, error) {
+	if t.mintMethod != "" {
+		return t.contract.Transact(opts, t.mintMethod, transfer.To, transfer.Amount)
+	}
+	return t.contract.Transact(opts, "transfer", transfer.To, transfer.Amount)
+}
+
+// TotalSupply returns amount of tokens in circulation on chain
+func (t *erc20) TotalSupply(ctx context.Context) (*big.Int, error) {
+	supply := new(*big.Int)
+	if err := t.contract.Call(&bind.CallOpts{Context: ctx}, supply, "totalSupply"); err != nil {
+		return nil, errors.Wrap(err, "failed to get total supply of erc20 contract")
+	}
+	return *supply, nil
+}
+
+func (t *erc20) Delivered(log types.Log, transfer Transfer) bool {
+	var event struct {
+		From  common.Address
+		To    common.Address
+		Value *big.Int
+	}
+	if !t.event(&event, "Transfer", log) {
+		return false
+	}
+
+	// minted tokens are transferred from zero address
+	if t.mintMethod != "" && event.From != (common.Address{}) {
+		return false
+	}
+
+	return event.To == transfer.To && event.Value.Cmp(transfer.Amount) == 0
+}
+
+// TransferData returns calldata of ERC20 `transfer`, used to send tokens from contract wallets
+func TransferData(transfer Transfer) ([]byte, error) {
+	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse erc20 ABI")
+	}
+	data, err := parsed.Pack("transfer", transfer.To, transfer.Amount)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pack transfer call")
+	}
+	return data, nil
+}
@@ -0,0 +1,57 @@
+package token
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const erc721ABI = `[
+	{"constant":false,"inputs":[{"name":"_from","type":"address"},{"name":"_to","type":"address"},{"name":"_tokenId","type":"uint256"}],"name":"safeTransferFrom","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":true,"name":"tokenId","type":"uint256"}],"name":"Transfer","type":"event"}
+]`
+
+type erc721 struct {
+	*bound
+}
+
+func newERC721(address common.Address, backend bind.ContractBackend) (*erc721, error) {
+	b, err := newBound(erc721ABI, address, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &erc721{bound: b}, nil
+}
+
+// Decimals of ERC-721 are always zero, as each token is a unique item
+func (t *erc721) Decimals(ctx context.Context) (uint32, error) {
+	return 0, nil
+}
+
+func (t *erc721) Send(opts *bind.TransactOpts, transfer Transfer) (*types.Transaction, error) {
+	if transfer.TokenID == nil {
+		return nil, errors.New("token id is required for erc721 transfer")
+	}
+	if transfer.Amount.Cmp(big.NewInt(1)) != 0 {
+		return nil, errors.New("erc721 transfer amount must be 1")
+	}
+	return t.contract.Transact(opts, "safeTransferFrom", opts.From, transfer.To, transfer.TokenID)
+}
+
+func (t *erc721) Delivered(log types.Log, transfer Transfer) bool {
+	var event struct {
+		From common.Address
+		To   common.Address
+		// named after ABI argument, so log can be unpacked into it
+		TokenId *big.Int
+	}
+	if transfer.TokenID == nil || !t.event(&event, "Transfer", log) {
+		return false
+	}
+
+	return event.To == transfer.To && event.TokenId.Cmp(transfer.TokenID) == 0
+}
@@ -0,0 +1,144 @@
+package token
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	StandardERC20   = "erc20"
+	StandardERC721  = "erc721"
+	StandardERC1155 = "erc1155"
+)
+
+const (
+	// ModeTransfer sends tokens from pre-funded hot wallet
+	ModeTransfer = "transfer"
+	// ModeMint mints tokens to destination, hot wallet must be allowed to mint
+	ModeMint = "mint"
+	// ModeBridge sends tokens through bridge contract, which puts request ref on-chain
+	ModeBridge = "bridge"
+
+	// DefaultMintMethod is used by mint mode if method is not configured
+	DefaultMintMethod = "mint"
+)
+
+// Params describe token contract asset is withdrawn to
+type Params struct {
+	Standard string
+	Address  common.Address
+	// TokenID is an id of the asset within ERC-1155 contract
+	TokenID *big.Int
+	// Mode is a way ERC20 tokens are delivered, transfer by default
+	Mode string
+	// MintMethod is a name of `method(address to, uint256 amount)` used in mint mode
+	MintMethod string
+	// Bridge is a contract used in bridge mode
+	Bridge *Bridge
+}
+
+// Transfer is a delivery of withdrawn tokens to destination
+type Transfer struct {
+	To     common.Address
+	Amount *big.Int
+	// TokenID is set only for non fungible tokens
+	TokenID *big.Int
+	// Ref is a reference of withdraw request, used only by bridge contract
+	Ref common.Hash
+}
+
+// Contract sends withdrawals of a single asset and recognizes their on-chain events
+type Contract interface {
+	// Decimals returns number of fractional digits of token amounts
+	Decimals(ctx context.Context) (uint32, error)
+	// Send sends transaction delivering transfer from opts.From
+	Send(opts *bind.TransactOpts, transfer Transfer) (*types.Transaction, error)
+	// Delivered returns true if log is an event of the transfer delivery
+	Delivered(log types.Log, transfer Transfer) bool
+}
+
+// Supplier is a contract which total supply is known
+type Supplier interface {
+	TotalSupply(ctx context.Context) (*big.Int, error)
+}
+
+// New binds token contract described by params
+func New(params Params, backend bind.ContractBackend) (Contract, error) {
+	switch params.Standard {
+	case StandardERC20:
+		switch params.Mode {
+		case "", ModeTransfer:
+			return newERC20(params.Address, "", backend)
+		case ModeMint:
+			method := params.MintMethod
+			if method == "" {
+				method = DefaultMintMethod
+			}
+			return newERC20(params.Address, method, backend)
+		case ModeBridge:
+			if params.Bridge == nil {
+				return nil, errors.New("bridge contract is required in bridge mode")
+			}
+			return newBridge(params.Address, *params.Bridge, backend)
+		default:
+			return nil, errors.From(errors.New("unsupported erc20 mode"), logan.F{"mode": params.Mode})
+		}
+	case StandardERC721:
+		return newERC721(params.Address, backend)
+	case StandardERC1155:
+		if params.TokenID == nil {
+			return nil, errors.New("token id is required for erc1155 contract")
+		}
+		return newERC1155(params.Address, params.TokenID, backend)
+	default:
+		return nil, errors.From(errors.New("unsupported token standard"), logan.F{"standard": params.Standard})
+	}
+}
+
+// bound is a contract with parsed ABI
+type bound struct {
+	address  common.Address
+	abi      abi.ABI
+	contract *bind.BoundContract
+}
+
+func newBound(rawABI string, address common.Address, backend bind.ContractBackend) (*bound, error) {
+	parsed, err := abi.JSON(strings.NewReader(rawABI))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse contract ABI")
+	}
+
+	return &bound{
+		address:  address,
+		abi:      parsed,
+		contract: bind.NewBoundContract(address, parsed, backend, backend, backend),
+	}, nil
+}
+
+// event unpacks log into out, returns false if log is not the named event emitted by the contract
+func (b *bound) event(out interface{}, name string, log types.Log) bool {
+	return b.emitted(name, log) && b.contract.UnpackLog(out, name, log) == nil
+}
+
+// eventFields unpacks log into map by argument names, returns false if log is not the named event emitted by the contract
+func (b *bound) eventFields(name string, log types.Log) (map[string]interface{}, bool) {
+	if !b.emitted(name, log) {
+		return nil, false
+	}
+
+	fields := make(map[string]interface{})
+	return fields, b.contract.UnpackLogIntoMap(fields, name, log) == nil
+}
+
+// emitted returns true if log is the named event emitted by the contract
+func (b *bound) emitted(name string, log types.Log) bool {
+	return !log.Removed && log.Address == b.address && len(log.Topics) > 0 && log.Topics[0] == b.abi.Events[name].Id()
+}
@@ -0,0 +1,192 @@
+package token
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	contractAddress = common.HexToAddress("0x8576acc5c05d6ce88f4e49bf65bdf0c62f91353c")
+	from            = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	to              = common.HexToAddress("0x2222222222222222222222222222222222222222")
+	transferTopic   = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+)
+
+func TestERC20Delivered(t *testing.T) {
+	contract, err := New(Params{Standard: StandardERC20, Address: contractAddress}, nil)
+	assert.NoError(t, err)
+
+	log := types.Log{
+		Address: contractAddress,
+		Topics:  []common.Hash{transferTopic, from.Hash(), to.Hash()},
+		Data:    common.BigToHash(big.NewInt(42)).Bytes(),
+	}
+	transfer := Transfer{To: to, Amount: big.NewInt(42)}
+
+	assert.True(t, contract.Delivered(log, transfer))
+	assert.False(t, contract.Delivered(log, Transfer{To: to, Amount: big.NewInt(41)}))
+	assert.False(t, contract.Delivered(log, Transfer{To: from, Amount: big.NewInt(42)}))
+
+	foreign := log
+	foreign.Address = from
+	assert.False(t, contract.Delivered(foreign, transfer))
+
+	removed := log
+	removed.Removed = true
+	assert.False(t, contract.Delivered(removed, transfer))
+}
+
+func TestERC20MintDelivered(t *testing.T) {
+	contract, err := New(Params{Standard: StandardERC20, Address: contractAddress, Mode: ModeMint, MintMethod: "issue"}, nil)
+	assert.NoError(t, err)
+
+	minted := types.Log{
+		Address: contractAddress,
+		Topics:  []common.Hash{transferTopic, common.Address{}.Hash(), to.Hash()},
+		Data:    common.BigToHash(big.NewInt(42)).Bytes(),
+	}
+	transfer := Transfer{To: to, Amount: big.NewInt(42)}
+	assert.True(t, contract.Delivered(minted, transfer))
+
+	transferred := minted
+	transferred.Topics = []common.Hash{transferTopic, from.Hash(), to.Hash()}
+	assert.False(t, contract.Delivered(transferred, transfer))
+
+	_, err = New(Params{Standard: StandardERC20, Address: contractAddress, Mode: "burn"}, nil)
+	assert.Error(t, err)
+}
+
+func TestERC721Delivered(t *testing.T) {
+	contract, err := New(Params{Standard: StandardERC721, Address: contractAddress}, nil)
+	assert.NoError(t, err)
+
+	log := types.Log{
+		Address: contractAddress,
+		Topics:  []common.Hash{transferTopic, from.Hash(), to.Hash(), common.BigToHash(big.NewInt(7))},
+	}
+
+	assert.True(t, contract.Delivered(log, Transfer{To: to, Amount: big.NewInt(1), TokenID: big.NewInt(7)}))
+	assert.False(t, contract.Delivered(log, Transfer{To: to, Amount: big.NewInt(1), TokenID: big.NewInt(8)}))
+	assert.False(t, contract.Delivered(log, Transfer{To: to, Amount: big.NewInt(1)}))
+}
+
+func TestERC1155Delivered(t *testing.T) {
+	contract, err := New(Params{Standard: StandardERC1155, Address: contractAddress, TokenID: big.NewInt(7)}, nil)
+	assert.NoError(t, err)
+
+	topic := crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+	log := types.Log{
+		Address: contractAddress,
+		Topics:  []common.Hash{topic, from.Hash(), from.Hash(), to.Hash()},
+		Data:    append(common.BigToHash(big.NewInt(7)).Bytes(), common.BigToHash(big.NewInt(5)).Bytes()...),
+	}
+
+	assert.True(t, contract.Delivered(log, Transfer{To: to, Amount: big.NewInt(5)}))
+	assert.False(t, contract.Delivered(log, Transfer{To: to, Amount: big.NewInt(6)}))
+
+	other, err := New(Params{Standard: StandardERC1155, Address: contractAddress, TokenID: big.NewInt(8)}, nil)
+	assert.NoError(t, err)
+	assert.False(t, other.Delivered(log, Transfer{To: to, Amount: big.NewInt(5)}))
+}
+
+const bridgeABI = `[
+{"type":"function","name":"withdraw","inputs":[{"name":"token","type":"address"},{"name":"to","type":"address"},{"name":"amount","type":"uint256"},{"name":"requestRef","type":"bytes32"}],"outputs":[]},
+{"type":"event","name":"Withdrawn","anonymous":false,"inputs":[{"name":"token","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"requestRef","type":"bytes32","indexed":true},{"name":"amount","type":"uint256","indexed":false}]},
+{"type":"event","name":"Released","anonymous":false,"inputs":[{"name":"token","type":"address","indexed":false},{"name":"to","type":"address","indexed":true},{"name":"amount","type":"uint256","indexed":false},{"name":"ref","type":"bytes32","indexed":false}]},
+{"type":"event","name":"Refunded","anonymous":false,"inputs":[{"name":"to","type":"address","indexed":true},{"name":"amount","type":"uint256","indexed":false},{"name":"ref","type":"bytes32","indexed":false}]}
+]`
+
+func TestBridgeDelivered(t *testing.T) {
+	ref, err := RequestRef("42", "0x"+strings.Repeat("ab", 32))
+	assert.NoError(t, err)
+	other, err := RequestRef("43", "0x"+strings.Repeat("ab", 32))
+	assert.NoError(t, err)
+	transfer := Transfer{To: to, Amount: big.NewInt(42), Ref: ref}
+
+	bridge := Bridge{Address: from, ABI: bridgeABI}
+	contract, err := New(Params{Standard: StandardERC20, Address: contractAddress, Mode: ModeBridge, Bridge: &bridge}, nil)
+	assert.NoError(t, err)
+
+	withdrawnTopic := crypto.Keccak256Hash([]byte("Withdrawn(address,address,bytes32,uint256)"))
+	withdrawn := types.Log{
+		Address: from,
+		Topics:  []common.Hash{withdrawnTopic, contractAddress.Hash(), to.Hash(), ref},
+		Data:    common.BigToHash(big.NewInt(42)).Bytes(),
+	}
+	assert.True(t, contract.Delivered(withdrawn, transfer))
+	assert.False(t, contract.Delivered(withdrawn, Transfer{To: to, Amount: big.NewInt(42), Ref: other}))
+	assert.False(t, contract.Delivered(withdrawn, Transfer{To: to, Amount: big.NewInt(42)}))
+
+	// event carrying request ref must match the rest of transfer as well
+	assert.False(t, contract.Delivered(withdrawn, Transfer{To: from, Amount: big.NewInt(42), Ref: ref}))
+	assert.False(t, contract.Delivered(withdrawn, Transfer{To: to, Amount: big.NewInt(41), Ref: ref}))
+	foreignToken := types.Log{
+		Address: from,
+		Topics:  []common.Hash{withdrawnTopic, from.Hash(), to.Hash(), ref},
+		Data:    common.BigToHash(big.NewInt(42)).Bytes(),
+	}
+	assert.False(t, contract.Delivered(foreignToken, transfer))
+
+	// arguments are matched by type, so they may be non indexed as well
+	bridge.Event = "Released"
+	contract, err = New(Params{Standard: StandardERC20, Address: contractAddress, Mode: ModeBridge, Bridge: &bridge}, nil)
+	assert.NoError(t, err)
+
+	released := types.Log{
+		Address: from,
+		Topics:  []common.Hash{crypto.Keccak256Hash([]byte("Released(address,address,uint256,bytes32)")), to.Hash()},
+		Data:    append(append(contractAddress.Hash().Bytes(), common.BigToHash(big.NewInt(42)).Bytes()...), ref.Bytes()...),
+	}
+	assert.True(t, contract.Delivered(released, transfer))
+	assert.False(t, contract.Delivered(withdrawn, transfer))
+
+	// event which does not carry token can't be verified
+	bridge.Event = "Refunded"
+	_, err = New(Params{Standard: StandardERC20, Address: contractAddress, Mode: ModeBridge, Bridge: &bridge}, nil)
+	assert.Error(t, err)
+
+	bridge.Event = ""
+	bridge.Method = "Released"
+	_, err = New(Params{Standard: StandardERC20, Address: contractAddress, Mode: ModeBridge, Bridge: &bridge}, nil)
+	assert.Error(t, err)
+}
+
+func TestMultisendDelivered(t *testing.T) {
+	multisendAddress := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	multisend, err := NewMultisend(multisendAddress, "", contractAddress, nil)
+	assert.NoError(t, err)
+
+	transferLog := func(index uint, src, dst common.Address, value int64) *types.Log {
+		return &types.Log{
+			Address: contractAddress,
+			Topics:  []common.Hash{transferTopic, src.Hash(), dst.Hash()},
+			Data:    common.BigToHash(big.NewInt(value)).Bytes(),
+			Index:   index,
+		}
+	}
+	logs := []*types.Log{
+		// multisend is funded first, as Disperse `disperseToken` does
+		transferLog(3, from, multisendAddress, 12),
+		transferLog(4, multisendAddress, to, 5),
+		transferLog(5, multisendAddress, from, 7),
+	}
+
+	index, ok := multisend.Delivered(logs, 0, Transfer{To: to, Amount: big.NewInt(5)})
+	assert.True(t, ok)
+	assert.EqualValues(t, 4, index)
+
+	index, ok = multisend.Delivered(logs, 1, Transfer{To: from, Amount: big.NewInt(7)})
+	assert.True(t, ok)
+	assert.EqualValues(t, 5, index)
+
+	_, ok = multisend.Delivered(logs, 1, Transfer{To: to, Amount: big.NewInt(5)})
+	assert.False(t, ok)
+	_, ok = multisend.Delivered(logs, 2, Transfer{To: to, Amount: big.NewInt(5)})
+	assert.False(t, ok)
+}
@@ -0,0 +1,85 @@
+package token
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// DefaultMultisendMethod is a method of Disperse contract pulling tokens from sender directly to recipients
+const DefaultMultisendMethod = "disperseTokenSimple"
+
+// multisendABI is an ABI of `method(address token, address[] recipients, uint256[] values)`
+const multisendABI = `[{"constant":false,"inputs":[{"name":"token","type":"address"},{"name":"recipients","type":"address[]"},{"name":"values","type":"uint256[]"}],"name":%s,"outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"}]`
+
+// Multisend sends ERC20 tokens to several recipients in a single transaction,
+// sender must allow multisend contract to spend its tokens
+type Multisend struct {
+	*bound
+	token  *erc20
+	method string
+}
+
+// NewMultisend binds multisend contract at address sending tokens of ERC20 contract at tokenAddress
+func NewMultisend(address common.Address, method string, tokenAddress common.Address, backend bind.ContractBackend) (*Multisend, error) {
+	if method == "" {
+		method = DefaultMultisendMethod
+	}
+	name, err := json.Marshal(method)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal multisend method name")
+	}
+	b, err := newBound(fmt.Sprintf(multisendABI, name), address, backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to bind multisend contract")
+	}
+	token, err := newERC20(tokenAddress, "", backend)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to bind token contract")
+	}
+
+	return &Multisend{bound: b, token: token, method: method}, nil
+}
+
+// Send sends transaction delivering all transfers from opts.From, in order
+func (m *Multisend) Send(opts *bind.TransactOpts, transfers []Transfer) (*types.Transaction, error) {
+	recipients := make([]common.Address, 0, len(transfers))
+	values := make([]*big.Int, 0, len(transfers))
+	for _, transfer := range transfers {
+		recipients = append(recipients, transfer.To)
+		values = append(values, transfer.Amount)
+	}
+
+	return m.contract.Transact(opts, m.method, m.token.address, recipients, values)
+}
+
+// Delivered finds token Transfer event of the batch entry at index and returns its log index.
+// Events are matched to entries in order, transfers to multisend contract itself are skipped as they only fund it.
+func (m *Multisend) Delivered(logs []*types.Log, index int, transfer Transfer) (uint, bool) {
+	position := 0
+	for _, log := range logs {
+		if log.Removed {
+			return 0, false
+		}
+
+		var event struct {
+			From  common.Address
+			To    common.Address
+			Value *big.Int
+		}
+		if !m.token.event(&event, "Transfer", *log) || event.To == m.address {
+			continue
+		}
+		if position == index {
+			return log.Index, m.token.Delivered(*log, transfer)
+		}
+		position++
+	}
+
+	return 0, false
+}